@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/database"
+	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
+	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+)
+
+const usage = `Usage: authz <command> [flags]
+
+Commands:
+  apply       show how casbin_rule differs from the policy file for the roles it names, and apply the difference with --confirm
+  reconcile   report role assignments found in only one of user_roles and Casbin, and optionally repair them
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "apply":
+		apply(os.Args[2:])
+	case "reconcile":
+		reconcile(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// cliActor records the policy changes made by the commands in the authz audit log
+var cliActor = casbinService.AuditActor{Source: casbinService.AuditSourceCLI}
+
+// apply prints the diff between the policy file and casbin_rule, and applies it with --confirm
+// Without --confirm it exits with 1 when they differ, so it can check an environment for drift
+func apply(args []string) {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	file := flags.String("file", casbinService.DefaultPolicyPath, "policy file to apply")
+	confirm := flags.Bool("confirm", false, "apply the diff instead of only printing it")
+	flags.Parse(args)
+
+	initAuthz()
+
+	policyService := service.NewPolicyService(
+		roleRepo.NewRoleRepository(database.DB),
+		casbinService.NewCasbinService(database.DB).WithActor(cliActor),
+	)
+
+	plan, err := policyService.Plan(*file)
+	if err != nil {
+		log.Fatalf("Failed to compare the policy file: %v", err)
+	}
+
+	for _, role := range plan.UnknownRoles {
+		fmt.Printf("warning: role %q is not in the roles table, it can't be assigned to users\n", role)
+	}
+
+	if plan.Diff.IsEmpty() {
+		fmt.Printf("casbin_rule matches %s\n", plan.File)
+		return
+	}
+
+	for _, line := range plan.Diff.Lines() {
+		fmt.Println(line)
+	}
+
+	if !*confirm {
+		fmt.Println("dry run, re-run with --confirm to apply")
+		os.Exit(1)
+	}
+
+	if err := policyService.Apply(plan); err != nil {
+		log.Fatalf("Failed to apply the policy file: %v", err)
+	}
+	fmt.Printf("applied %s\n", plan.File)
+}
+
+// reconcile reports drift between user_roles and Casbin, exiting with 1 when drift is left unrepaired
+func reconcile(args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	source := flags.String("source", dto.RoleReconcileSourceDB, `side trusted when repairing: "db" (user_roles) or "casbin"`)
+	repair := flags.Bool("repair", false, "repair the drift instead of only reporting it")
+	flags.Parse(args)
+
+	initAuthz()
+
+	userRoleService := service.NewUserRoleService(
+		userRepo.NewUserRepository(database.DB),
+		roleRepo.NewRoleRepository(database.DB),
+		casbinService.NewCasbinService(database.DB).WithActor(cliActor),
+	)
+
+	report, err := userRoleService.Reconcile(*source, *repair)
+	if err != nil {
+		log.Fatalf("Failed to reconcile user roles: %v", err)
+	}
+
+	if len(report.Drift) == 0 {
+		fmt.Println("user_roles and Casbin are in sync")
+		return
+	}
+
+	for _, drift := range report.Drift {
+		status := ""
+		switch {
+		case drift.Repaired:
+			status = " (repaired)"
+		case drift.Error != "":
+			status = " (repair failed: " + drift.Error + ")"
+		}
+		fmt.Printf("%-18s user:%d role %q (id %d)%s\n", drift.Kind, drift.UserID, drift.RoleName, drift.RoleID, status)
+	}
+	fmt.Printf("%d drifted assignments, %d repaired from %s\n", len(report.Drift), report.Repaired, report.Source)
+
+	if report.Repaired < len(report.Drift) {
+		os.Exit(1)
+	}
+}
+
+// initAuthz connects to the database and loads the Casbin policies
+func initAuthz() {
+	cfg := config.LoadConfig()
+	database.Init(cfg.DB)
+
+	modelPath := filepath.Join("config", "casbin", "model.conf")
+	if _, err := casbinService.InitCasbin(database.DB, modelPath); err != nil {
+		log.Fatalf("Failed to initialize Casbin: %v", err)
+	}
+
+	// Running servers reload the policies once the changes are committed
+	if cfg.Auth.PolicyWatcherChannel != "" {
+		casbinService.StartNotifier(database.DB, cfg.Auth.PolicyWatcherChannel)
+	}
+}
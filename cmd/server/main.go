@@ -3,11 +3,20 @@ package main
 import (
 	"log"
 	"path/filepath"
+	"time"
 
 	"github.com/FeisalDy/nogo/config"
+	appService "github.com/FeisalDy/nogo/internal/application/service"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/mailer"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/FeisalDy/nogo/internal/database"
+	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
 	"github.com/FeisalDy/nogo/internal/router"
+	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
 )
 
 func main() {
@@ -15,6 +24,16 @@ func main() {
 	if err := config.InitializeApp(cfg.App); err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
+	utils.ConfigureTokenExpiration(cfg.Auth)
+	if err := utils.ConfigurePasswordHashing(cfg.Password); err != nil {
+		log.Fatalf("Invalid password hashing configuration: %v", err)
+	}
+	if err := utils.InitPasswordPolicy(cfg.Password); err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	if err := utils.InitJWTKeys(cfg.Auth); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
 	database.Init(cfg.DB)
 
 	modelPath := filepath.Join("config", "casbin", "model.conf")
@@ -23,11 +42,48 @@ func main() {
 		log.Fatalf("Failed to initialize Casbin: %v", err)
 	}
 	log.Println("Casbin initialized successfully")
+	if cfg.Auth.PolicyWatcherChannel != "" {
+		if _, err := casbinService.StartWatcher(database.DB, cfg.DB.DSN(), cfg.Auth.PolicyWatcherChannel); err != nil {
+			log.Fatalf("Failed to start Casbin watcher: %v", err)
+		}
+		log.Printf("Casbin watcher listening on %q", cfg.Auth.PolicyWatcherChannel)
+	}
+	// The roles owned by the policy file are read once, the role API refuses to change them
+	if err := casbinService.LoadManagedRoles(casbinService.DefaultPolicyPath); err != nil {
+		log.Fatalf("Failed to load the managed roles of the policy file: %v", err)
+	}
+	middleware.ConfigureExplainOnDeny(cfg.App.Debug)
+
+	revocationStore, err := authService.InitRevocationStore(database.DB)
+	if err != nil {
+		log.Fatalf("Failed to initialize token revocation store: %v", err)
+	}
+	revocationStore.StartSync(30 * time.Second)
+
+	statusStore, err := userService.InitStatusStore(database.DB)
+	if err != nil {
+		log.Fatalf("Failed to initialize user status store: %v", err)
+	}
+	statusStore.StartSync(30 * time.Second)
+
+	userRoleService := appService.NewUserRoleService(
+		userRepo.NewUserRepository(database.DB),
+		roleRepo.NewRoleRepository(database.DB),
+		casbinService.NewCasbinService(database.DB),
+	)
+	userRoleService.StartExpirySweeper(time.Minute)
+
+	if _, err := mailer.InitMailer(cfg.Mail); err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
 
 	// Run all seeders (includes Casbin policies)
 	database.RunSeeds()
 
-	r := router.SetupRoutes(database.DB, cfg.App)
+	r := router.SetupRoutes(database.DB, cfg)
+	if err := router.VerifyRoutes(r, database.DB); err != nil {
+		log.Fatalf("Failed to verify route permissions: %v", err)
+	}
 
 	serverAddr := ":" + cfg.App.Port
 	log.Printf("Starting server on %s", serverAddr)
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -17,6 +19,12 @@ type DBConfig struct {
 	DBName   string
 }
 
+// DSN returns the connection string of the database
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
+		c.Host, c.User, c.Password, c.DBName, c.Port)
+}
+
 type AppConfig struct {
 	Environment    string        // development, staging, production
 	Port           string        // server port
@@ -25,12 +33,89 @@ type AppConfig struct {
 	LogLevel       string        // log level (debug, info, warn, error)
 	Debug          bool          // debug mode
 	RequestTimeout time.Duration // request timeout
+	TrustedProxies []string      // proxies whose X-Forwarded-For is used for the client IP, none by default
+}
+
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration // lifetime of JWT access tokens
+	RefreshTokenTTL time.Duration // lifetime of opaque refresh tokens
+	JWTAlgorithm    string        // HS256, RS256 or EdDSA
+	JWTSecret       string        // HMAC secret, only used with HS256
+	JWTKeysDir      string        // directory of <kid>.pem private keys and <kid>.pub.pem verify-only keys
+	JWTActiveKeyID  string        // kid of the key used to sign new tokens
+	JWTIssuer       string        // "iss" claim set on issued tokens and required on validation
+
+	RequireEmailVerification bool          // refuse logins until the email address is verified
+	EmailVerificationTTL     time.Duration // lifetime of email verification tokens
+	EmailVerificationURL     string        // link sent in verification emails, the token is appended as ?token=
+	PasswordResetTTL         time.Duration // lifetime of password reset tokens
+	PasswordResetURL         string        // link sent in password reset emails, the token is appended as ?token=
+	MFAIssuer                string        // issuer shown in authenticator apps
+	MFAPendingTTL            time.Duration // time to enter the second factor after the password step
+	LoginMaxAttempts         int           // failed attempts per email before it is locked
+	LoginIPMaxAttempts       int           // failed attempts per IP address before it is locked
+	LoginAttemptWindow       time.Duration // failures older than this are forgotten
+	LoginLockoutBase         time.Duration // first lockout, doubled on every further failure
+	LoginLockoutMax          time.Duration // upper bound of the lockout
+	PasswordResetMaxRequests int           // password reset requests per email within the attempt window
+	PolicyWatcherChannel     string        // Postgres channel announcing Casbin policy changes to other instances, empty to disable
+}
+
+// PasswordConfig configures password hashing and the policy new passwords must meet
+// Stored hashes weaker than the hashing settings are upgraded on the next login
+type PasswordConfig struct {
+	Algorithm         string // bcrypt or argon2id
+	BcryptCost        int
+	Argon2Memory      uint32 // memory in KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	MinLength         int
+	RequireUpper      bool
+	RequireLower      bool
+	RequireDigit      bool
+	RequireSymbol     bool
+	BreachedListPath  string // file with one known breached password per line, empty to disable
+}
+
+type MailConfig struct {
+	Driver       string // smtp, file or log
+	From         string // sender address
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FilePath     string // outbox file used by the file driver
+}
+
+// OIDCProviderConfig configures one OAuth2 / OpenID Connect login provider
+// With an IssuerURL the endpoints are discovered; plain OAuth2 providers set them explicitly
+type OIDCProviderConfig struct {
+	Name         string // used in the route, e.g. /auth/oidc/google/authorize
+	IssuerURL    string // OIDC issuer, used for discovery and to check ID tokens
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	AuthURL      string // optional, overrides discovery
+	TokenURL     string // optional, overrides discovery
+	UserInfoURL  string // optional, used when the provider returns no ID token
+	JWKSURL      string // optional, overrides discovery
+	SubjectClaim string // claim holding the stable account ID, "sub" by default ("id" for GitHub)
+}
+
+type OIDCConfig struct {
+	RedirectBaseURL string        // callback is <RedirectBaseURL>/<provider>/callback
+	StateTTL        time.Duration // time to complete a login at the provider
+	Providers       []OIDCProviderConfig
 }
 
 // Config holds all configuration
 type Config struct {
-	DB  DBConfig
-	App AppConfig
+	DB       DBConfig
+	App      AppConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	Mail     MailConfig
+	OIDC     OIDCConfig
 }
 
 // LoadConfig loads all application configuration from environment variables
@@ -41,8 +126,12 @@ func LoadConfig() Config {
 	}
 
 	return Config{
-		App: LoadAppConfig(),
-		DB:  LoadDBConfig(),
+		App:      LoadAppConfig(),
+		DB:       LoadDBConfig(),
+		Auth:     LoadAuthConfig(),
+		Password: LoadPasswordConfig(),
+		Mail:     LoadMailConfig(),
+		OIDC:     LoadOIDCConfig(),
 	}
 }
 
@@ -66,6 +155,7 @@ func LoadAppConfig() AppConfig {
 		LogLevel:       getEnv("LOG_LEVEL", "info"),
 		Debug:          debug,
 		RequestTimeout: time.Duration(timeout) * time.Second,
+		TrustedProxies: strings.FieldsFunc(getEnv("TRUSTED_PROXIES", ""), func(r rune) bool { return r == ',' || r == ' ' }),
 	}
 }
 
@@ -80,6 +170,186 @@ func LoadDBConfig() DBConfig {
 	}
 }
 
+// LoadAuthConfig loads the authentication configuration from environment variables
+func LoadAuthConfig() AuthConfig {
+	accessTTL, err := strconv.Atoi(getEnv("ACCESS_TOKEN_TTL_MINUTES", "15"))
+	if err != nil {
+		accessTTL = 15
+	}
+
+	refreshTTL, err := strconv.Atoi(getEnv("REFRESH_TOKEN_TTL_HOURS", "720"))
+	if err != nil {
+		refreshTTL = 720
+	}
+
+	requireVerification, err := strconv.ParseBool(getEnv("REQUIRE_EMAIL_VERIFICATION", "false"))
+	if err != nil {
+		requireVerification = false
+	}
+
+	verificationTTL, err := strconv.Atoi(getEnv("EMAIL_VERIFICATION_TTL_HOURS", "48"))
+	if err != nil {
+		verificationTTL = 48
+	}
+
+	resetTTL, err := strconv.Atoi(getEnv("PASSWORD_RESET_TTL_MINUTES", "60"))
+	if err != nil {
+		resetTTL = 60
+	}
+
+	mfaPendingTTL, err := strconv.Atoi(getEnv("MFA_PENDING_TTL_MINUTES", "5"))
+	if err != nil {
+		mfaPendingTTL = 5
+	}
+
+	loginMaxAttempts, err := strconv.Atoi(getEnv("LOGIN_MAX_ATTEMPTS", "5"))
+	if err != nil {
+		loginMaxAttempts = 5
+	}
+
+	loginIPMaxAttempts, err := strconv.Atoi(getEnv("LOGIN_IP_MAX_ATTEMPTS", "20"))
+	if err != nil {
+		loginIPMaxAttempts = 20
+	}
+
+	loginWindow, err := strconv.Atoi(getEnv("LOGIN_ATTEMPT_WINDOW_MINUTES", "15"))
+	if err != nil {
+		loginWindow = 15
+	}
+
+	lockoutBase, err := strconv.Atoi(getEnv("LOGIN_LOCKOUT_BASE_SECONDS", "30"))
+	if err != nil {
+		lockoutBase = 30
+	}
+
+	lockoutMax, err := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MAX_MINUTES", "60"))
+	if err != nil {
+		lockoutMax = 60
+	}
+
+	resetMaxRequests, err := strconv.Atoi(getEnv("PASSWORD_RESET_MAX_REQUESTS", "3"))
+	if err != nil {
+		resetMaxRequests = 3
+	}
+
+	return AuthConfig{
+		AccessTokenTTL:  time.Duration(accessTTL) * time.Minute,
+		RefreshTokenTTL: time.Duration(refreshTTL) * time.Hour,
+		JWTAlgorithm:    getEnv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		JWTKeysDir:      getEnv("JWT_KEYS_DIR", "config/keys"),
+		JWTActiveKeyID:  getEnv("JWT_ACTIVE_KEY_ID", ""),
+		JWTIssuer:       getEnv("JWT_ISSUER", ""),
+
+		RequireEmailVerification: requireVerification,
+		EmailVerificationTTL:     time.Duration(verificationTTL) * time.Hour,
+		EmailVerificationURL:     getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/v1/auth/verify-email"),
+		PasswordResetTTL:         time.Duration(resetTTL) * time.Minute,
+		PasswordResetURL:         getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		MFAIssuer:                getEnv("MFA_ISSUER", "nogo"),
+		MFAPendingTTL:            time.Duration(mfaPendingTTL) * time.Minute,
+		LoginMaxAttempts:         loginMaxAttempts,
+		LoginIPMaxAttempts:       loginIPMaxAttempts,
+		LoginAttemptWindow:       time.Duration(loginWindow) * time.Minute,
+		LoginLockoutBase:         time.Duration(lockoutBase) * time.Second,
+		LoginLockoutMax:          time.Duration(lockoutMax) * time.Minute,
+		PasswordResetMaxRequests: resetMaxRequests,
+		PolicyWatcherChannel:     getEnv("CASBIN_WATCHER_CHANNEL", "casbin_policy"),
+	}
+}
+
+// LoadPasswordConfig loads the password hashing and policy configuration from environment variables
+func LoadPasswordConfig() PasswordConfig {
+	bcryptCost, err := strconv.Atoi(getEnv("PASSWORD_BCRYPT_COST", "12"))
+	if err != nil {
+		bcryptCost = 12
+	}
+
+	argon2Memory, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_MEMORY_KB", "65536"), 10, 32)
+	if err != nil {
+		argon2Memory = 65536
+	}
+
+	argon2Iterations, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_ITERATIONS", "3"), 10, 32)
+	if err != nil {
+		argon2Iterations = 3
+	}
+
+	argon2Parallelism, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_PARALLELISM", "2"), 10, 8)
+	if err != nil {
+		argon2Parallelism = 2
+	}
+
+	minLength, err := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+	if err != nil {
+		minLength = 8
+	}
+
+	return PasswordConfig{
+		Algorithm:         strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt")),
+		BcryptCost:        bcryptCost,
+		Argon2Memory:      uint32(argon2Memory),
+		Argon2Iterations:  uint32(argon2Iterations),
+		Argon2Parallelism: uint8(argon2Parallelism),
+		MinLength:         minLength,
+		RequireUpper:      getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:      getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		RequireDigit:      getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol:     getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		BreachedListPath:  getEnv("PASSWORD_BREACHED_LIST", "config/breached_passwords.txt"),
+	}
+}
+
+// LoadMailConfig loads the outgoing mail configuration from environment variables
+func LoadMailConfig() MailConfig {
+	return MailConfig{
+		Driver:       getEnv("MAIL_DRIVER", "log"),
+		From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		FilePath:     getEnv("MAIL_FILE_PATH", "tmp/mail.log"),
+	}
+}
+
+// LoadOIDCConfig loads the social login providers from environment variables
+// OIDC_PROVIDERS lists the provider names; each one reads OIDC_<NAME>_* variables
+func LoadOIDCConfig() OIDCConfig {
+	stateTTL, err := strconv.Atoi(getEnv("OIDC_STATE_TTL_MINUTES", "10"))
+	if err != nil {
+		stateTTL = 10
+	}
+
+	cfg := OIDCConfig{
+		RedirectBaseURL: getEnv("OIDC_REDIRECT_BASE_URL", "http://localhost:8080/api/v1/auth/oidc"),
+		StateTTL:        time.Duration(stateTTL) * time.Minute,
+	}
+
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg.Providers = append(cfg.Providers, OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+			AuthURL:      getEnv(prefix+"AUTH_URL", ""),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  getEnv(prefix+"USERINFO_URL", ""),
+			JWKSURL:      getEnv(prefix+"JWKS_URL", ""),
+			SubjectClaim: getEnv(prefix+"SUBJECT_CLAIM", "sub"),
+		})
+	}
+
+	return cfg
+}
+
 // InitializeApp initializes global application settings
 func InitializeApp(config AppConfig) error {
 	// Set timezone
@@ -141,3 +411,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
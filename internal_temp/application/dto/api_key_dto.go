@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+)
+
+// CreateAPIKeyDTO represents the request to create a personal API key
+// Scopes are "resource:action" permissions the user already has, e.g. "novels:read"
+type CreateAPIKeyDTO struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponseDTO holds a new API key, which is only shown once
+type CreateAPIKeyResponseDTO struct {
+	Key    string        `json:"key"`
+	APIKey *model.APIKey `json:"api_key"`
+}
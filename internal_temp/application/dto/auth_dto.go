@@ -0,0 +1,95 @@
+package dto
+
+import "time"
+
+// RefreshTokenDTO represents the request to rotate a refresh token
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutDTO represents the request to log out the current session
+// RefreshToken is optional; when given, its token family is revoked too
+type LogoutDTO struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// VerifyEmailDTO represents the request to verify an email address
+// The token can be sent as a JSON body or as the ?token= query parameter of the emailed link
+type VerifyEmailDTO struct {
+	Token string `json:"token" form:"token" validate:"required"`
+}
+
+// ForgotPasswordDTO represents the request to send a password reset link
+type ForgotPasswordDTO struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordDTO represents the request to set a new password with a reset token
+type ResetPasswordDTO struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+}
+
+// MFAChallengeDTO is returned by login instead of tokens when the user has two-factor authentication enabled
+// The MFA token must be exchanged at /auth/mfa/verify together with a valid code
+type MFAChallengeDTO struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"` // mfa token lifetime in seconds
+}
+
+// MFAVerifyLoginDTO represents the second step of a login for users with two-factor authentication
+// Code is a TOTP code or one of the recovery codes
+type MFAVerifyLoginDTO struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFACodeDTO represents a request that must be confirmed with a TOTP or recovery code
+type MFACodeDTO struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAEnrollResponseDTO holds the TOTP secret to add to an authenticator app
+type MFAEnrollResponseDTO struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesResponseDTO holds recovery codes, which are only shown once
+type MFARecoveryCodesResponseDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// UnlockAccountDTO represents an admin request to lift a login lockout
+// IP is optional; when given, the lockout of that IP address is lifted too
+type UnlockAccountDTO struct {
+	UserID uint   `json:"user_id" validate:"required"`
+	IP     string `json:"ip" validate:"omitempty,ip"`
+}
+
+// OIDCCallbackDTO represents the redirect back from a login provider
+// Accepted as query parameters (browser redirect) or as a JSON body (forwarded by a frontend)
+type OIDCCallbackDTO struct {
+	Code  string `json:"code" form:"code" validate:"required"`
+	State string `json:"state" form:"state" validate:"required"`
+	Error string `json:"error" form:"error"`
+}
+
+// OIDCAuthorizeResponseDTO holds the provider URL to send the user to
+type OIDCAuthorizeResponseDTO struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SessionDTO represents a login session of the current user
+// Current marks the session the request was made with
+type SessionDTO struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+)
+
+// AuthzExplainQueryDTO represents a request to explain an authorization decision
+// User is a user ID or email. NovelID checks the decision within the novel's domain, so team roles apply,
+// and ResourceID is the resource whose ownership is checked for "own" scoped actions
+type AuthzExplainQueryDTO struct {
+	User       string `form:"user" validate:"required"`
+	Resource   string `form:"resource" validate:"required,max=50"`
+	Action     string `form:"action" validate:"required,max=50"`
+	NovelID    uint   `form:"novel_id"`
+	ResourceID uint   `form:"resource_id"`
+}
+
+// AuthzConditionDTO represents a condition checked besides the Casbin policies
+// Passed is nil when the condition could not be evaluated
+type AuthzConditionDTO struct {
+	Name   string `json:"name"`
+	Passed *bool  `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AuthzExplanationDTO represents an authorization decision and how it was reached
+// Checks holds one Casbin decision per action enforced, e.g. "write:any" and "write:own" for ownership scoped actions
+type AuthzExplanationDTO struct {
+	UserID     uint                         `json:"user_id"`
+	Resource   string                       `json:"resource"`
+	Action     string                       `json:"action"`
+	Domain     string                       `json:"domain,omitempty"`
+	Allowed    bool                         `json:"allowed"`
+	Checks     []*casbinService.Explanation `json:"checks"`
+	Conditions []AuthzConditionDTO          `json:"conditions"`
+}
+
+// AuthzRouteDTO represents a route and the access it requires
+// Roles lists the roles granted the route's permission directly, roles inheriting from them pass as well
+type AuthzRouteDTO struct {
+	casbinService.RoutePermission
+	Roles []string `json:"roles,omitempty"`
+}
+
+// AuthzAuditQueryDTO represents a request to list the authz audit log, newest first
+// Since and Until are RFC 3339 times, Cursor is the next_cursor of the previous page
+type AuthzAuditQueryDTO struct {
+	ActorID   uint       `form:"actor_id"`
+	Action    string     `form:"action" validate:"max=50"`
+	Target    string     `form:"target" validate:"max=255"`
+	RequestID string     `form:"request_id" validate:"max=64"`
+	Since     *time.Time `form:"since"`
+	Until     *time.Time `form:"until"`
+	Cursor    uint       `form:"cursor"`
+	Limit     int        `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// AuthzAuditPageInfoDTO represents the position of a page of the authz audit log
+// NextCursor is nil on the last page
+type AuthzAuditPageInfoDTO struct {
+	NextCursor *uint `json:"next_cursor"`
+	HasMore    bool  `json:"has_more"`
+}
+
+// AuthzAuditMetadataDTO represents the size of a page of the authz audit log
+type AuthzAuditMetadataDTO struct {
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+}
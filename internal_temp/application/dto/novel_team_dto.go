@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// InviteTeamMemberDTO represents the request to invite a user to a novel team
+type InviteTeamMemberDTO struct {
+	UserID uint   `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required"`
+}
+
+// AssignTeamRoleDTO represents the request to set a user's role in a novel team
+type AssignTeamRoleDTO struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// TeamMemberDTO represents a member or pending invitation of a novel team
+type TeamMemberDTO struct {
+	UserID     uint       `json:"user_id"`
+	Username   *string    `json:"username,omitempty"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  *uint      `json:"invited_by,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NovelTeamDTO represents the team working on a novel
+type NovelTeamDTO struct {
+	NovelID   uint            `json:"novel_id"`
+	TeamRoles []string        `json:"team_roles"`
+	Members   []TeamMemberDTO `json:"members"`
+}
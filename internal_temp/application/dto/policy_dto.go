@@ -0,0 +1,13 @@
+package dto
+
+import (
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+)
+
+// PolicyPlanDTO represents the changes needed for the Casbin rules to match the policy file
+// UnknownRoles are roles named in the file that don't exist in the roles table, so they can't be assigned
+type PolicyPlanDTO struct {
+	File         string                    `json:"file"`
+	Diff         *casbinService.PolicyDiff `json:"diff"`
+	UnknownRoles []string                  `json:"unknown_roles"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+// UpdateProfileDTO represents a partial update of the current user's profile
+// Omitted fields are left unchanged. An empty bio clears it and avatar_media_id 0 removes the avatar
+type UpdateProfileDTO struct {
+	Username      *string `json:"username" validate:"omitempty,min=3,max=30"`
+	Bio           *string `json:"bio" validate:"omitempty,max=1000"`
+	AvatarMediaID *uint   `json:"avatar_media_id"`
+}
+
+// ChangePasswordDTO represents the request to change the current user's password
+// Accounts that only use social login have no current password, they send a two-factor code
+// or make the change shortly after logging in
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password"`
+	MFACode         string `json:"mfa_code"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+}
+
+// ChangeEmailDTO represents the request to switch the current user to a new email address
+// The change takes effect once the link mailed to the new address is opened
+// Accounts without a password confirm it like a password change, see ChangePasswordDTO
+type ChangeEmailDTO struct {
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password"`
+	MFACode         string `json:"mfa_code"`
+}
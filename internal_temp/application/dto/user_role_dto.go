@@ -1,9 +1,13 @@
 package dto
 
+import "time"
+
 // AssignRoleToUserDTO represents the request to assign a role to a user
+// The role is removed automatically at ExpiresAt; without it the assignment is permanent
 type AssignRoleToUserDTO struct {
-	UserID uint `json:"user_id" validate:"required"`
-	RoleID uint `json:"role_id" validate:"required"`
+	UserID    uint       `json:"user_id" validate:"required"`
+	RoleID    uint       `json:"role_id" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 // RemoveRoleFromUserDTO represents the request to remove a role from a user
@@ -20,3 +24,35 @@ type UserRoleAssignmentDTO struct {
 	UserEmail string `json:"user_email,omitempty"`
 	Username  string `json:"username,omitempty"`
 }
+
+// Kinds of drift between user_roles and Casbin grouping rules
+const (
+	RoleDriftMissingInCasbin = "missing_in_casbin" // user_roles row without a Casbin rule
+	RoleDriftMissingInDB     = "missing_in_db"     // Casbin rule without a user_roles row
+)
+
+// Sides trusted when repairing drift
+const (
+	RoleReconcileSourceDB     = "db"     // user_roles is right, Casbin rules are added or removed
+	RoleReconcileSourceCasbin = "casbin" // Casbin is right, user_roles rows are added or removed
+)
+
+// RoleDriftDTO represents a role assignment found on only one side
+// RoleID is 0 when the Casbin rule names a role that doesn't exist in the roles table
+type RoleDriftDTO struct {
+	Kind     string `json:"kind"`
+	UserID   uint   `json:"user_id"`
+	RoleID   uint   `json:"role_id,omitempty"`
+	RoleName string `json:"role_name"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RoleReconcileReportDTO represents the drift found between user_roles and Casbin
+// Source is the side that was trusted when repairing, "db" or "casbin"
+type RoleReconcileReportDTO struct {
+	Source   string         `json:"source"`
+	Repair   bool           `json:"repair"`
+	Drift    []RoleDriftDTO `json:"drift"`
+	Repaired int            `json:"repaired"`
+}
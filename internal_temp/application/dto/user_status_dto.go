@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	userModel "github.com/FeisalDy/nogo/internal/user/model"
+)
+
+// UpdateUserStatusDTO represents an admin request to change a user's account status
+// Until is required when suspending and ignored otherwise
+type UpdateUserStatusDTO struct {
+	Status string     `json:"status" validate:"required,oneof=active suspended banned deactivated"`
+	Reason string     `json:"reason" validate:"max=500"`
+	Until  *time.Time `json:"until"`
+}
+
+// UserStatusDTO represents a user's account status and its history
+// Status is the status in effect now, so an expired suspension shows as active
+type UserStatusDTO struct {
+	UserID    uint                         `json:"user_id"`
+	Status    string                       `json:"status"`
+	Reason    *string                      `json:"reason,omitempty"`
+	Until     *time.Time                   `json:"until,omitempty"`
+	ChangedAt *time.Time                   `json:"changed_at,omitempty"`
+	ChangedBy *uint                        `json:"changed_by,omitempty"`
+	History   []userModel.UserStatusChange `json:"history"`
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// APIKeyHandler handles personal API key requests
+type APIKeyHandler struct {
+	apiKeyService *authService.APIKeyService
+	validator     *validator.Validate
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler
+func NewAPIKeyHandler(apiKeySvc *authService.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeySvc,
+		validator:     validator.New(),
+	}
+}
+
+// Create creates an API key for the current user
+// POST /api/v1/api-keys
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	var req dto.CreateAPIKeyDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	key, plainKey, err := h.apiKeyService.CreateKey(userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	response := dto.CreateAPIKeyResponseDTO{
+		Key:    plainKey,
+		APIKey: key,
+	}
+	utils.RespondSuccess(c, http.StatusCreated, response, "API key created. Store it now, it won't be shown again")
+}
+
+// List lists the API keys of the current user
+// GET /api/v1/api-keys
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(userID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, keys)
+}
+
+// Revoke revokes an API key of the current user
+// DELETE /api/v1/api-keys/:id
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(userID, uint(keyID)); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "API key revoked")
+}
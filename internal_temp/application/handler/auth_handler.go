@@ -3,8 +3,11 @@ package handler
 import (
 	"net/http"
 
+	"github.com/FeisalDy/nogo/internal/application/dto"
 	"github.com/FeisalDy/nogo/internal/application/service"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
 	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	userDto "github.com/FeisalDy/nogo/internal/user/dto"
 	"github.com/gin-gonic/gin"
@@ -50,27 +53,203 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	username := ""
-	if user.Username != nil {
-		username = *user.Username
+	response, err := h.authService.RegistrationResponse(user, clientInfo(c))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
 	}
-	token, err := utils.GenerateToken(user.ID, user.Email, username)
+
+	if response.Token == "" {
+		utils.RespondSuccess(c, http.StatusCreated, response, "Registration successful, verify your email to log in")
+		return
+	}
+	utils.RespondSuccess(c, http.StatusCreated, response, "Registration successful")
+}
+
+// Login handles user login and issues an access token plus a refresh token
+// Users with two-factor authentication get an MFA token to exchange at /auth/mfa/verify instead
+// POST /api/v1/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var loginDTO userDto.LoginUserDTO
+	if err := c.ShouldBindJSON(&loginDTO); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeUserValidation)
+		return
+	}
+
+	if err := h.validator.Struct(loginDTO); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeUserValidation)
+		return
+	}
+
+	response, challenge, err := h.authService.Login(&loginDTO, clientInfo(c))
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
 
-	response := userDto.AuthResponseDTO{
-		Token: token,
-		User: userDto.UserResponseDTO{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			AvatarURL: user.AvatarURL,
-			Bio:       user.Bio,
-			Status:    user.Status,
-		},
+	if challenge != nil {
+		utils.RespondSuccess(c, http.StatusOK, challenge, "Two-factor authentication required")
+		return
 	}
 
-	utils.RespondSuccess(c, http.StatusCreated, response, "Registration successful")
+	utils.RespondSuccess(c, http.StatusOK, response, "Login successful")
+}
+
+// Refresh rotates a refresh token and issues a new token pair
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	response, err := h.authService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, response, "Token refreshed successfully")
+}
+
+// Logout revokes the current access token and optionally its refresh token
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, exists := middleware.GetTokenClaims(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	// The body is optional, so only bind it when one was sent
+	var req dto.LogoutDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+			return
+		}
+	}
+
+	if err := h.authService.Logout(claims, req.RefreshToken); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Logout successful")
+}
+
+// LogoutAll revokes every token of the current user on all devices
+// POST /api/v1/auth/logout-all
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Logged out from all devices")
+}
+
+// VerifyEmail confirms an email address using the token from the verification email
+// GET /api/v1/auth/verify-email?token=...
+// POST /api/v1/auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailDTO
+	if err := c.ShouldBind(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Email verified successfully")
+}
+
+// ResendVerification sends a new verification email to the current user
+// POST /api/v1/auth/verify-email/resend
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	if err := h.authService.ResendVerification(userID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Verification email sent")
+}
+
+// ForgotPassword sends a password reset link to the given email
+// Responds the same way whether or not the email is registered, unless requests are throttled
+// POST /api/v1/auth/password/forgot
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email, clientInfo(c).IP); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "If an account with that email exists, a password reset link has been sent")
+}
+
+// ResetPassword sets a new password using the token from the reset email
+// POST /api/v1/auth/password/reset
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.Password); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Password has been reset, please log in again")
+}
+
+// clientInfo describes the device a request comes from, for recording login sessions
+func clientInfo(c *gin.Context) authService.ClientInfo {
+	return authService.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
 }
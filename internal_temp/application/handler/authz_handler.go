@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// AuthzHandler handles admin requests about authorization decisions
+type AuthzHandler struct {
+	authzService *service.AuthzService
+	validator    *validator.Validate
+}
+
+// NewAuthzHandler creates a new instance of AuthzHandler
+func NewAuthzHandler(authzService *service.AuthzService) *AuthzHandler {
+	return &AuthzHandler{
+		authzService: authzService,
+		validator:    validator.New(),
+	}
+}
+
+// Explain explains whether a user may perform an action on a resource, and why
+// GET /api/v1/authz/explain?user=..&resource=..&action=..
+func (h *AuthzHandler) Explain(c *gin.Context) {
+	var req dto.AuthzExplainQueryDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	explanation, err := h.authzService.Explain(req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, explanation)
+}
+
+// GetRoutes lists every route with the access it requires
+// GET /api/v1/authz/routes
+func (h *AuthzHandler) GetRoutes(c *gin.Context) {
+	routes, err := h.authzService.GetRoutes()
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, routes)
+}
+
+// GetAuditLog lists the changes made to the policies, newest first
+// GET /api/v1/authz/audit?actor_id=..&action=..&target=..&request_id=..&since=..&until=..&cursor=..&limit=..
+func (h *AuthzHandler) GetAuditLog(c *gin.Context) {
+	var req dto.AuthzAuditQueryDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	entries, pageInfo, err := h.authzService.GetAuditLog(&req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccessWithPagination(c, http.StatusOK, entries, pageInfo, dto.AuthzAuditMetadataDTO{
+		Count: len(entries),
+		Limit: req.Limit,
+	})
+}
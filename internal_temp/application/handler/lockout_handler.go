@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// LockoutHandler handles admin requests about login lockouts
+type LockoutHandler struct {
+	authService *service.AuthService
+	throttle    *authService.LoginThrottleService
+	validator   *validator.Validate
+}
+
+// NewLockoutHandler creates a new instance of LockoutHandler
+func NewLockoutHandler(authSvc *service.AuthService, throttle *authService.LoginThrottleService) *LockoutHandler {
+	return &LockoutHandler{
+		authService: authSvc,
+		throttle:    throttle,
+		validator:   validator.New(),
+	}
+}
+
+// GetLockouts lists the emails, IP addresses and MFA users that are locked right now
+// GET /api/v1/auth/lockouts
+func (h *LockoutHandler) GetLockouts(c *gin.Context) {
+	lockouts, err := h.throttle.GetLockedThrottles()
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, lockouts)
+}
+
+// Unlock lifts the lockout of an account
+// POST /api/v1/auth/lockouts/unlock
+func (h *LockoutHandler) Unlock(c *gin.Context) {
+	var req dto.UnlockAccountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.UnlockAccount(req.UserID, req.IP); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Account unlocked")
+}
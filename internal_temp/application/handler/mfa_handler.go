@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// MFAHandler handles TOTP two-factor authentication requests
+type MFAHandler struct {
+	authService *service.AuthService
+	mfaService  *authService.MFAService
+	validator   *validator.Validate
+}
+
+// NewMFAHandler creates a new instance of MFAHandler
+func NewMFAHandler(authSvc *service.AuthService, mfaSvc *authService.MFAService) *MFAHandler {
+	return &MFAHandler{
+		authService: authSvc,
+		mfaService:  mfaSvc,
+		validator:   validator.New(),
+	}
+}
+
+// VerifyLogin exchanges the MFA token from login and a TOTP or recovery code for tokens
+// POST /api/v1/auth/mfa/verify
+func (h *MFAHandler) VerifyLogin(c *gin.Context) {
+	var req dto.MFAVerifyLoginDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	response, err := h.authService.VerifyMFALogin(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, response, "Login successful")
+}
+
+// GetStatus returns whether two-factor authentication is enabled for the current user
+// GET /api/v1/auth/mfa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(userID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, status)
+}
+
+// Enroll starts a TOTP enrollment and returns the secret and otpauth URI
+// POST /api/v1/auth/mfa/enroll
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	response, err := h.authService.EnrollMFA(userID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, response, "Scan the URI with an authenticator app and confirm with a code")
+}
+
+// Enable confirms the enrollment with a first TOTP code and returns the recovery codes
+// POST /api/v1/auth/mfa/enable
+func (h *MFAHandler) Enable(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.Enable(userID, req.Code)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, dto.MFARecoveryCodesResponseDTO{RecoveryCodes: codes}, "Two-factor authentication enabled, store the recovery codes safely")
+}
+
+// Disable turns two-factor authentication off
+// POST /api/v1/auth/mfa/disable
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(userID, req.Code); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user
+// POST /api/v1/auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, dto.MFARecoveryCodesResponseDTO{RecoveryCodes: codes}, "Recovery codes regenerated")
+}
+
+// bindCode reads the current user and the confirmation code of the request
+func (h *MFAHandler) bindCode(c *gin.Context) (uint, *dto.MFACodeDTO, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return 0, nil, false
+	}
+
+	var req dto.MFACodeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return 0, nil, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return 0, nil, false
+	}
+
+	return userID, &req, true
+}
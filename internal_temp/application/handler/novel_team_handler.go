@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// NovelTeamHandler handles requests about the team working on a novel
+type NovelTeamHandler struct {
+	novelTeamService *service.NovelTeamService
+	validator        *validator.Validate
+}
+
+// NewNovelTeamHandler creates a new instance of NovelTeamHandler
+func NewNovelTeamHandler(novelTeamService *service.NovelTeamService) *NovelTeamHandler {
+	return &NovelTeamHandler{
+		novelTeamService: novelTeamService,
+		validator:        validator.New(),
+	}
+}
+
+// GetTeam lists the members and pending invitations of a novel team
+// GET /api/v1/novels/:id/team
+func (h *NovelTeamHandler) GetTeam(c *gin.Context) {
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	team, err := h.novelTeamService.GetTeam(novelID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, team)
+}
+
+// Invite invites a user to a novel team
+// POST /api/v1/novels/:id/team/invitations
+func (h *NovelTeamHandler) Invite(c *gin.Context) {
+	inviterID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.InviteTeamMemberDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeTeamValidation)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeTeamValidation)
+		return
+	}
+
+	if err := h.novelTeamService.WithActor(middleware.GetAuditActor(c)).Invite(novelID, inviterID, req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusCreated, nil, "Invitation sent")
+}
+
+// AcceptInvitation joins a novel team the current user was invited to
+// POST /api/v1/novels/:id/team/invitations/accept
+func (h *NovelTeamHandler) AcceptInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.novelTeamService.WithActor(middleware.GetAuditActor(c)).AcceptInvitation(novelID, userID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Invitation accepted")
+}
+
+// DeclineInvitation declines the current user's invitation to a novel team
+// POST /api/v1/novels/:id/team/invitations/decline
+func (h *NovelTeamHandler) DeclineInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.novelTeamService.DeclineInvitation(novelID, userID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Invitation declined")
+}
+
+// AssignRole sets a user's role in a novel team
+// PUT /api/v1/novels/:id/team/members/:user_id
+func (h *NovelTeamHandler) AssignRole(c *gin.Context) {
+	assignerID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseUintParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var req dto.AssignTeamRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeTeamValidation)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeTeamValidation)
+		return
+	}
+
+	if err := h.novelTeamService.WithActor(middleware.GetAuditActor(c)).AssignRole(novelID, userID, assignerID, req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Team role assigned")
+}
+
+// RemoveMember removes a member or pending invitation from a novel team
+// DELETE /api/v1/novels/:id/team/members/:user_id
+func (h *NovelTeamHandler) RemoveMember(c *gin.Context) {
+	novelID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseUintParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.novelTeamService.WithActor(middleware.GetAuditActor(c)).RemoveMember(novelID, userID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Team member removed")
+}
+
+// parseUintParam reads an ID route parameter, responding with an error if it is invalid
+func parseUintParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return 0, false
+	}
+	return uint(id), true
+}
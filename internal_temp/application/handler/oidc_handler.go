@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// OIDCHandler handles social login through OAuth2 / OpenID Connect providers
+type OIDCHandler struct {
+	authService *service.AuthService
+	oidcService *authService.OIDCService
+	validator   *validator.Validate
+}
+
+// NewOIDCHandler creates a new instance of OIDCHandler
+func NewOIDCHandler(authSvc *service.AuthService, oidcSvc *authService.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		authService: authSvc,
+		oidcService: oidcSvc,
+		validator:   validator.New(),
+	}
+}
+
+// GetProviders lists the configured login providers
+// GET /api/v1/auth/oidc/providers
+func (h *OIDCHandler) GetProviders(c *gin.Context) {
+	utils.RespondSuccess(c, http.StatusOK, h.oidcService.ProviderNames())
+}
+
+// Authorize starts a login at the provider and returns the URL to send the user to
+// The login's state is set as a cookie the callback must come back with
+// GET /api/v1/auth/oidc/:provider/authorize
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authURL, state, err := h.oidcService.AuthorizationURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+	http.SetCookie(c.Writer, h.oidcService.StateCookie(c.Param("provider"), state))
+
+	utils.RespondSuccess(c, http.StatusOK, dto.OIDCAuthorizeResponseDTO{AuthorizationURL: authURL})
+}
+
+// Callback completes the login with the code and state returned by the provider
+// GET /api/v1/auth/oidc/:provider/callback?code=...&state=...
+// POST /api/v1/auth/oidc/:provider/callback
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req dto.OIDCCallbackDTO
+	if err := c.ShouldBind(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	// The user declined or the provider failed, there is no code to exchange
+	if req.Error != "" {
+		utils.RespondWithAppError(c, errors.ErrAuthOIDCFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	// The state is single-use, the cookie is removed whatever the outcome
+	browserState, _ := c.Cookie(authService.StateCookieName)
+	http.SetCookie(c.Writer, h.oidcService.ExpiredStateCookie(c.Param("provider")))
+
+	response, challenge, err := h.authService.LoginWithOIDC(c.Request.Context(), c.Param("provider"), req.Code, req.State, browserState, clientInfo(c))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	if challenge != nil {
+		utils.RespondSuccess(c, http.StatusOK, challenge, "Two-factor authentication required")
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, response, "Login successful")
+}
+
+// GetIdentities lists the external accounts linked to the current user
+// GET /api/v1/auth/oidc/identities
+func (h *OIDCHandler) GetIdentities(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	identities, err := h.oidcService.GetIdentities(userID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, identities)
+}
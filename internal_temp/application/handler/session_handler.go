@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FeisalDy/nogo/internal/application/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler handles requests to list and revoke the login sessions of the current user
+type SessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewSessionHandler creates a new instance of SessionHandler
+func NewSessionHandler(authSvc *service.AuthService) *SessionHandler {
+	return &SessionHandler{
+		authService: authSvc,
+	}
+}
+
+// List lists the active login sessions of the current user
+// GET /api/v1/profile/sessions
+func (h *SessionHandler) List(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	var currentSessionID uint
+	if claims, ok := middleware.GetTokenClaims(c); ok {
+		currentSessionID = claims.SessionID
+	}
+
+	sessions, err := h.authService.ListSessions(userID, currentSessionID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, sessions)
+}
+
+// Revoke signs the current user out of one of their sessions
+// DELETE /api/v1/profile/sessions/:id
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, uint(sessionID)); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Session revoked")
+}
@@ -3,22 +3,29 @@ package handler
 import (
 	"net/http"
 
+	"github.com/FeisalDy/nogo/internal/application/dto"
 	"github.com/FeisalDy/nogo/internal/application/service"
 	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 // UserProfileHandler handles user profile requests at the application layer
 // This is for operations that span multiple domains (User + Role + Casbin)
 type UserProfileHandler struct {
 	userProfileService *service.UserProfileService
+	authService        *service.AuthService
+	validator          *validator.Validate
 }
 
 // NewUserProfileHandler creates a new instance of UserProfileHandler
-func NewUserProfileHandler(userProfileService *service.UserProfileService) *UserProfileHandler {
+func NewUserProfileHandler(userProfileService *service.UserProfileService, authSvc *service.AuthService) *UserProfileHandler {
 	return &UserProfileHandler{
 		userProfileService: userProfileService,
+		authService:        authSvc,
+		validator:          validator.New(),
 	}
 }
 
@@ -40,3 +47,88 @@ func (h *UserProfileHandler) GetMe(c *gin.Context) {
 
 	utils.RespondSuccess(c, http.StatusOK, userWithPermissions, "User profile retrieved successfully")
 }
+
+// UpdateMe updates the current user's username, bio and avatar
+// PATCH /api/v1/profile/me
+func (h *UserProfileHandler) UpdateMe(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	var req dto.UpdateProfileDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	profile, err := h.userProfileService.UpdateProfile(userID, &req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, profile, "Profile updated")
+}
+
+// ChangePassword changes the current user's password and signs out their other sessions
+// POST /api/v1/profile/password
+func (h *UserProfileHandler) ChangePassword(c *gin.Context) {
+	claims, exists := middleware.GetTokenClaims(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	var req dto.ChangePasswordDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.ChangePassword(claims.UserID, claims.SessionID, req.CurrentPassword, req.MFACode, req.NewPassword, clientInfo(c).IP); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Password changed, your other sessions were signed out")
+}
+
+// ChangeEmail mails a confirmation link to the new email address
+// POST /api/v1/profile/email
+func (h *UserProfileHandler) ChangeEmail(c *gin.Context) {
+	claims, exists := middleware.GetTokenClaims(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	var req dto.ChangeEmailDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.authService.RequestEmailChange(claims.UserID, claims.SessionID, req.Email, req.CurrentPassword, req.MFACode, clientInfo(c).IP); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusAccepted, nil, "Check your new email address for a confirmation link")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/FeisalDy/nogo/internal/application/dto"
 	"github.com/FeisalDy/nogo/internal/application/service"
 	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -41,14 +42,15 @@ func (h *UserRoleHandler) AssignRoleToUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userRoleService.AssignRoleToUser(req.UserID, req.RoleID); err != nil {
+	if err := h.userRoleService.WithActor(middleware.GetAuditActor(c)).AssignRoleToUser(req.UserID, req.RoleID, req.ExpiresAt); err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
 
 	utils.RespondSuccess(c, http.StatusOK, gin.H{
-		"user_id": req.UserID,
-		"role_id": req.RoleID,
+		"user_id":    req.UserID,
+		"role_id":    req.RoleID,
+		"expires_at": req.ExpiresAt,
 	}, "Role assigned to user successfully")
 }
 
@@ -66,7 +68,7 @@ func (h *UserRoleHandler) RemoveRoleFromUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userRoleService.RemoveRoleFromUser(req.UserID, req.RoleID); err != nil {
+	if err := h.userRoleService.WithActor(middleware.GetAuditActor(c)).RemoveRoleFromUser(req.UserID, req.RoleID); err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
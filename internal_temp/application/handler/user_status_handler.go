@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	"github.com/FeisalDy/nogo/internal/application/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// UserStatusHandler handles admin requests about account statuses
+type UserStatusHandler struct {
+	userStatusService *service.UserStatusService
+	validator         *validator.Validate
+}
+
+// NewUserStatusHandler creates a new instance of UserStatusHandler
+func NewUserStatusHandler(userStatusService *service.UserStatusService) *UserStatusHandler {
+	return &UserStatusHandler{
+		userStatusService: userStatusService,
+		validator:         validator.New(),
+	}
+}
+
+// GetStatus returns a user's account status and its history
+// GET /api/v1/user-status/users/:user_id
+func (h *UserStatusHandler) GetStatus(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return
+	}
+
+	status, err := h.userStatusService.GetStatus(uint(userID))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, status)
+}
+
+// UpdateStatus suspends, bans, deactivates or reactivates a user
+// PUT /api/v1/user-status/users/:user_id
+func (h *UserStatusHandler) UpdateStatus(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return
+	}
+
+	var req dto.UpdateUserStatusDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	status, err := h.userStatusService.ChangeStatus(uint(userID), &req, adminID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, status, "Account status updated")
+}
@@ -1,46 +1,179 @@
 package application
 
 import (
+	"github.com/FeisalDy/nogo/config"
 	"github.com/FeisalDy/nogo/internal/application/handler"
 	"github.com/FeisalDy/nogo/internal/application/service"
+	authRepo "github.com/FeisalDy/nogo/internal/auth/repository"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/mailer"
 	"github.com/FeisalDy/nogo/internal/common/middleware"
+	commonRepo "github.com/FeisalDy/nogo/internal/common/repository"
+	mediaRepo "github.com/FeisalDy/nogo/internal/media/repository"
 	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
+	teamRepo "github.com/FeisalDy/nogo/internal/team/repository"
 	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(db *gorm.DB, router *gin.RouterGroup) {
+func RegisterRoutes(db *gorm.DB, router *gin.RouterGroup, cfg config.Config) {
 	userRepository := userRepo.NewUserRepository(db)
 	roleRepository := roleRepo.NewRoleRepository(db)
+	mediaRepository := mediaRepo.NewMediaRepository(db)
+	refreshTokenRepository := authRepo.NewRefreshTokenRepository(db)
+	verificationTokenRepository := authRepo.NewEmailVerificationTokenRepository(db)
+	resetTokenRepository := authRepo.NewPasswordResetTokenRepository(db)
+	mfaRepository := authRepo.NewMFARepository(db)
+	throttleRepository := authRepo.NewLoginThrottleRepository(db)
+	identityRepository := authRepo.NewUserIdentityRepository(db)
+	apiKeyRepository := authRepo.NewAPIKeyRepository(db)
+	sessionRepository := authRepo.NewUserSessionRepository(db)
+	teamMemberRepository := teamRepo.NewTeamMemberRepository(db)
+	authzAuditRepository := commonRepo.NewAuthzAuditRepository(db)
 	casbinSvc := casbinService.NewCasbinService(db)
+	userSvc := userService.NewUserService(userRepository).RequireEmailVerification(cfg.Auth.RequireEmailVerification)
+	tokenSvc := authService.NewTokenService(refreshTokenRepository, sessionRepository)
+	verifySvc := authService.NewEmailVerificationService(verificationTokenRepository, mailer.GetMailer(), cfg.Auth)
+	resetSvc := authService.NewPasswordResetService(resetTokenRepository, mailer.GetMailer(), cfg.Auth)
+	mfaSvc := authService.NewMFAService(mfaRepository, cfg.Auth)
+	throttleSvc := authService.NewLoginThrottleService(throttleRepository, cfg.Auth)
+	oidcSvc := authService.NewOIDCService(identityRepository, cfg.OIDC)
+	apiKeySvc := authService.NewAPIKeyService(apiKeyRepository, casbinSvc)
 
 	userRoleService := service.NewUserRoleService(userRepository, roleRepository, casbinSvc)
-	authService := service.NewAuthService(userRepository, roleRepository, casbinSvc)
-	userProfileService := service.NewUserProfileService(userRepository, roleRepository, casbinSvc)
+	authService := service.NewAuthService(userSvc, userRepository, casbinSvc, userRoleService, tokenSvc, verifySvc, resetSvc, mfaSvc, throttleSvc, oidcSvc)
+	userProfileService := service.NewUserProfileService(userRepository, roleRepository, mediaRepository, casbinSvc)
+	userStatusService := service.NewUserStatusService(userSvc, tokenSvc)
+	novelTeamService := service.NewNovelTeamService(teamMemberRepository, userRepository, roleRepository, casbinSvc)
+	authzService := service.NewAuthzService(userRepository, authzAuditRepository, casbinSvc)
 
 	userRoleHandler := handler.NewUserRoleHandler(userRoleService)
 	authHandler := handler.NewAuthHandler(authService)
-	userProfileHandler := handler.NewUserProfileHandler(userProfileService)
+	mfaHandler := handler.NewMFAHandler(authService, mfaSvc)
+	lockoutHandler := handler.NewLockoutHandler(authService, throttleSvc)
+	oidcHandler := handler.NewOIDCHandler(authService, oidcSvc)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeySvc)
+	sessionHandler := handler.NewSessionHandler(authService)
+	userProfileHandler := handler.NewUserProfileHandler(userProfileService, authService)
+	userStatusHandler := handler.NewUserStatusHandler(userStatusService)
+	novelTeamHandler := handler.NewNovelTeamHandler(novelTeamService)
+	authzHandler := handler.NewAuthzHandler(authzService)
 
 	authRoutes := router.Group("/auth")
 	{
-		authRoutes.POST("/register", authHandler.Register)
+		public := middleware.DeclareRoutes(authRoutes).Public()
+		public.POST("/register", authHandler.Register)
+		public.POST("/login", authHandler.Login)
+		public.POST("/refresh", authHandler.Refresh)
+		public.GET("/verify-email", authHandler.VerifyEmail)
+		public.POST("/verify-email", authHandler.VerifyEmail)
+		public.POST("/password/forgot", authHandler.ForgotPassword)
+		public.POST("/password/reset", authHandler.ResetPassword)
+
+		self := middleware.DeclareRoutes(authRoutes).Authenticated()
+		self.POST("/logout", middleware.AuthMiddleware(), middleware.SessionOnly(), authHandler.Logout)
+		self.POST("/logout-all", middleware.AuthMiddleware(), middleware.SessionOnly(), authHandler.LogoutAll)
+		self.POST("/verify-email/resend", middleware.AuthMiddleware(), middleware.SessionOnly(), authHandler.ResendVerification)
+	}
+
+	mfaRoutes := router.Group("/auth/mfa")
+	{
+		// Completes the password step, so it is authenticated by the pending MFA token instead
+		middleware.DeclareRoutes(mfaRoutes).Public().POST("/verify", mfaHandler.VerifyLogin)
+
+		self := middleware.DeclareRoutes(mfaRoutes).Authenticated()
+		self.GET("", middleware.AuthMiddleware(), middleware.SessionOnly(), mfaHandler.GetStatus)
+		self.POST("/enroll", middleware.AuthMiddleware(), middleware.SessionOnly(), mfaHandler.Enroll)
+		self.POST("/enable", middleware.AuthMiddleware(), middleware.SessionOnly(), mfaHandler.Enable)
+		self.POST("/disable", middleware.AuthMiddleware(), middleware.SessionOnly(), mfaHandler.Disable)
+		self.POST("/recovery-codes", middleware.AuthMiddleware(), middleware.SessionOnly(), mfaHandler.RegenerateRecoveryCodes)
+	}
+
+	oidcRoutes := router.Group("/auth/oidc")
+	{
+		public := middleware.DeclareRoutes(oidcRoutes).Public()
+		public.GET("/providers", oidcHandler.GetProviders)
+		public.GET("/:provider/authorize", oidcHandler.Authorize)
+		public.GET("/:provider/callback", oidcHandler.Callback)
+		public.POST("/:provider/callback", oidcHandler.Callback)
+
+		middleware.DeclareRoutes(oidcRoutes).Authenticated().GET("/identities", middleware.AuthMiddleware(), middleware.SessionOnly(), oidcHandler.GetIdentities)
+	}
+
+	lockoutRoutes := router.Group("/auth/lockouts")
+	lockoutRoutes.Use(middleware.AuthMiddleware())
+	{
+		routes := middleware.DeclareRoutes(lockoutRoutes)
+		routes.Permission("users", "read").GET("", lockoutHandler.GetLockouts)
+		routes.Permission("users", "write").POST("/unlock", lockoutHandler.Unlock)
+	}
+
+	// API keys can't manage API keys, so a leaked key can't mint new ones
+	apiKeyRoutes := router.Group("/api-keys")
+	apiKeyRoutes.Use(middleware.AuthMiddleware(), middleware.SessionOnly())
+	{
+		self := middleware.DeclareRoutes(apiKeyRoutes).Authenticated()
+		self.POST("", apiKeyHandler.Create)
+		self.GET("", apiKeyHandler.List)
+		self.DELETE("/:id", apiKeyHandler.Revoke)
 	}
 
 	profileRoutes := router.Group("/profile")
 	profileRoutes.Use(middleware.AuthMiddleware())
 	{
-		profileRoutes.GET("/me", userProfileHandler.GetMe)
+		self := middleware.DeclareRoutes(profileRoutes).Authenticated()
+		self.GET("/me", userProfileHandler.GetMe)
+		self.PATCH("/me", middleware.SessionOnly(), userProfileHandler.UpdateMe)
+		self.POST("/password", middleware.SessionOnly(), userProfileHandler.ChangePassword)
+		self.POST("/email", middleware.SessionOnly(), userProfileHandler.ChangeEmail)
+
+		self.GET("/sessions", middleware.SessionOnly(), sessionHandler.List)
+		self.DELETE("/sessions/:id", middleware.SessionOnly(), sessionHandler.Revoke)
 	}
 
 	userRoleRoutes := router.Group("/user-roles")
 	userRoleRoutes.Use(middleware.AuthMiddleware())
 	{
-		userRoleRoutes.POST("/assign", userRoleHandler.AssignRoleToUser)
-		userRoleRoutes.POST("/remove", userRoleHandler.RemoveRoleFromUser)
+		routes := middleware.DeclareRoutes(userRoleRoutes)
+		routes.Permission("roles", "write").POST("/assign", userRoleHandler.AssignRoleToUser)
+		routes.Permission("roles", "write").POST("/remove", userRoleHandler.RemoveRoleFromUser)
 
-		userRoleRoutes.GET("/users/:user_id/roles", userRoleHandler.GetUserRoles)
+		routes.Permission("roles", "read").GET("/users/:user_id/roles", userRoleHandler.GetUserRoles)
+	}
+
+	userStatusRoutes := router.Group("/user-status")
+	userStatusRoutes.Use(middleware.AuthMiddleware())
+	{
+		routes := middleware.DeclareRoutes(userStatusRoutes)
+		routes.Permission("users", "read").GET("/users/:user_id", userStatusHandler.GetStatus)
+		routes.Permission("users", "write").PUT("/users/:user_id", middleware.SessionOnly(), userStatusHandler.UpdateStatus)
+	}
+
+	// Team permissions are checked in the novel's domain, so team leads can manage their own team
+	novelTeamRoutes := router.Group("/novels/:id/team")
+	novelTeamRoutes.Use(middleware.AuthMiddleware(), middleware.NovelDomain("id"))
+	{
+		routes := middleware.DeclareRoutes(novelTeamRoutes)
+		routes.Permission("teams", "read").GET("", novelTeamHandler.GetTeam)
+		routes.Permission("teams", "write").POST("/invitations", novelTeamHandler.Invite)
+		routes.Permission("teams", "write").PUT("/members/:user_id", novelTeamHandler.AssignRole)
+		routes.Permission("teams", "write").DELETE("/members/:user_id", novelTeamHandler.RemoveMember)
+
+		// Invitations are answered by the invited user
+		self := routes.Authenticated()
+		self.POST("/invitations/accept", middleware.SessionOnly(), novelTeamHandler.AcceptInvitation)
+		self.POST("/invitations/decline", middleware.SessionOnly(), novelTeamHandler.DeclineInvitation)
+	}
+
+	authzRoutes := router.Group("/authz")
+	authzRoutes.Use(middleware.AuthMiddleware())
+	{
+		routes := middleware.DeclareRoutes(authzRoutes)
+		routes.Permission("authz", "read").GET("/explain", authzHandler.Explain)
+		routes.Permission("authz", "read").GET("/routes", authzHandler.GetRoutes)
+		routes.Permission("authz", "read").GET("/audit", authzHandler.GetAuditLog)
 	}
 }
@@ -1,36 +1,77 @@
 package service
 
 import (
+	"context"
+	stdErrors "errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	authModel "github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/oidc"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/FeisalDy/nogo/internal/database"
-	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
 	userDto "github.com/FeisalDy/nogo/internal/user/dto"
 	userModel "github.com/FeisalDy/nogo/internal/user/model"
 	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
 	"gorm.io/gorm"
 )
 
 // AuthService handles authentication operations that span multiple domains
 // This is part of the Application Layer
 type AuthService struct {
-	userRepo      *userRepo.UserRepository
-	roleRepo      *roleRepo.RoleRepository
-	casbinService *casbinService.CasbinService
+	userService     *userService.UserService
+	userRepo        *userRepo.UserRepository
+	casbinService   *casbinService.CasbinService
+	userRoleService *UserRoleService
+	tokenService    *authService.TokenService
+	verifyService   *authService.EmailVerificationService
+	resetService    *authService.PasswordResetService
+	mfaService      *authService.MFAService
+	throttle        *authService.LoginThrottleService
+	oidcService     *authService.OIDCService
+
+	// resetRequests holds the emails of password reset requests until the reset worker mails them
+	resetRequests chan string
 }
 
+// resetQueueSize bounds the password reset requests waiting to be mailed, further ones are dropped
+const resetQueueSize = 100
+
 // NewAuthService creates a new instance of AuthService
 func NewAuthService(
+	userSvc *userService.UserService,
 	userRepository *userRepo.UserRepository,
-	roleRepository *roleRepo.RoleRepository,
 	casbin *casbinService.CasbinService,
+	userRoleService *UserRoleService,
+	tokenService *authService.TokenService,
+	verifyService *authService.EmailVerificationService,
+	resetService *authService.PasswordResetService,
+	mfaService *authService.MFAService,
+	throttle *authService.LoginThrottleService,
+	oidcService *authService.OIDCService,
 ) *AuthService {
-	return &AuthService{
-		userRepo:      userRepository,
-		roleRepo:      roleRepository,
-		casbinService: casbin,
+	service := &AuthService{
+		userService:     userSvc,
+		userRepo:        userRepository,
+		casbinService:   casbin,
+		userRoleService: userRoleService,
+		tokenService:    tokenService,
+		verifyService:   verifyService,
+		resetService:    resetService,
+		mfaService:      mfaService,
+		throttle:        throttle,
+		oidcService:     oidcService,
+		resetRequests:   make(chan string, resetQueueSize),
 	}
+
+	go service.sendPasswordResets()
+	return service
 }
 
 // Register handles user registration with default role assignment
@@ -38,57 +79,644 @@ func NewAuthService(
 // 1. Creates a new user (User domain)
 // 2. Assigns default "user" role (Role domain)
 // 3. Syncs with Casbin for authorization
+// 4. Sends an email verification link (Auth domain)
 func (s *AuthService) Register(registerDTO *userDto.RegisterUserDTO) (*userModel.User, error) {
+	// 1. Check if user already exists
+	existingUser, _ := s.userRepo.GetUserByEmail(registerDTO.Email)
+	if existingUser != nil {
+		return nil, errors.ErrUserAlreadyExists
+	}
+
+	if err := userService.EnsureHandleAvailable(s.userRepo, registerDTO.Username, 0); err != nil {
+		return nil, err
+	}
+
+	// 2. Check the password policy and hash the password, before the transaction holds policy changes
+	if err := utils.ValidatePassword(registerDTO.Password); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(registerDTO.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &userModel.User{
+		Username: &registerDTO.Username,
+		Email:    registerDTO.Email,
+		Password: &hashedPassword,
+	}
+
 	var userCreated *userModel.User
+	err = s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		// 3. Create user
+		userCreated, err = s.userRepo.WithTx(tx).CreateUser(user)
+		if err != nil {
+			return userService.HandleConflictError(err)
+		}
+
+		// 4. Assign default "user" role (user_roles table and Casbin)
+		return s.userRoleService.AssignDefaultRoleToNewUser(tx, casbin, userCreated.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The account exists at this point, a failed email can be re-sent later
+	if err := s.verifyService.SendVerification(userCreated.ID, userCreated.Email); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", userCreated.ID, err)
+	}
+
+	return userCreated, nil
+}
+
+// VerifyEmail consumes a verification token and marks the user's email as verified
+// Tokens mailed for an email change switch the user to the confirmed new address,
+// and the previous address is told about the change
+func (s *AuthService) VerifyEmail(plainToken string) error {
+	var oldEmail, newEmail string
 
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		// 1. Check if user already exists
-		existingUser, _ := s.userRepo.WithTx(tx).GetUserByEmail(registerDTO.Email)
-		if existingUser != nil {
-			return errors.ErrUserAlreadyExists
+		token, err := s.verifyService.ConsumeToken(tx, plainToken)
+		if err != nil {
+			return err
 		}
 
-		// 2. Hash password
-		hashedPassword, err := utils.HashPassword(registerDTO.Password)
+		if token.Purpose == authModel.EmailTokenPurposeChange {
+			user, err := s.userRepo.WithTx(tx).GetUserByID(token.UserID)
+			if err != nil {
+				return errors.ErrAuthVerifyInvalid
+			}
+
+			if existingUser, _ := s.userRepo.WithTx(tx).GetUserByEmail(token.Email); existingUser != nil && existingUser.ID != user.ID {
+				return errors.ErrUserEmailTaken
+			}
+
+			if err := s.userRepo.WithTx(tx).ChangeEmail(user.ID, token.Email, time.Now()); err != nil {
+				return err
+			}
+
+			oldEmail, newEmail = user.Email, token.Email
+			return nil
+		}
+
+		verified, err := s.userRepo.WithTx(tx).MarkEmailVerified(token.UserID, token.Email, time.Now())
 		if err != nil {
 			return err
 		}
+		if !verified {
+			return errors.ErrAuthVerifyInvalid
+		}
 
-		// 3. Create user
-		user := &userModel.User{
-			Username: &registerDTO.Username,
-			Email:    registerDTO.Email,
-			Password: &hashedPassword,
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if oldEmail != "" && oldEmail != newEmail {
+		if err := s.verifyService.SendEmailChangedNotice(oldEmail, newEmail); err != nil {
+			log.Printf("Failed to send email change notice to %s: %v", oldEmail, err)
 		}
+	}
+	return nil
+}
 
-		userCreated, err = s.userRepo.WithTx(tx).CreateUser(user)
+// ResendVerification sends a new verification link to a user whose email is not verified yet
+func (s *AuthService) ResendVerification(userID uint) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+
+	if user.IsEmailVerified() {
+		return errors.ErrAuthEmailVerified
+	}
+
+	return s.verifyService.SendVerification(user.ID, user.Email)
+}
+
+// Login verifies the user's credentials and issues an access/refresh token pair
+// This is a cross-domain operation that:
+// 1. Refuses locked emails and IP addresses (Auth domain)
+// 2. Verifies credentials and counts failed attempts (User domain)
+// 3. For users with two-factor authentication, returns an MFA challenge instead of tokens (Auth domain)
+// 4. Starts a login session with a refresh token that starts a new token family (Auth domain)
+func (s *AuthService) Login(loginDTO *userDto.LoginUserDTO, client authService.ClientInfo) (*userDto.AuthResponseDTO, *dto.MFAChallengeDTO, error) {
+	if err := s.throttle.CheckLogin(loginDTO.Email, client.IP); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userService.Login(loginDTO)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserInvalidCredentials) {
+			if recordErr := s.throttle.RecordLoginFailure(loginDTO.Email, client.IP); recordErr != nil {
+				log.Printf("Failed to record login failure: %v", recordErr)
+			}
+		}
+		return nil, nil, err
+	}
+
+	if err := s.throttle.RecordLoginSuccess(loginDTO.Email); err != nil {
+		log.Printf("Failed to reset login failures of user %d: %v", user.ID, err)
+	}
+
+	return s.completeLogin(user, client)
+}
+
+// LoginWithOIDC completes a social login from the provider callback
+// This is a cross-domain operation that:
+// 1. Exchanges the authorization code for the external identity (Auth domain)
+// 2. Finds the linked user or links an existing user with the same email, verified on both sides (User domain)
+// 3. Otherwise creates a user with the default role, like Register (User and Role domain)
+// 4. Continues like a password login, including the MFA challenge
+func (s *AuthService) LoginWithOIDC(ctx context.Context, provider, code, state, browserState string, client authService.ClientInfo) (*userDto.AuthResponseDTO, *dto.MFAChallengeDTO, error) {
+	identity, err := s.oidcService.Authenticate(ctx, provider, code, state, browserState)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user *userModel.User
+	userID, err := s.oidcService.FindLinkedUserID(database.DB, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userID != 0 {
+		user, err = s.userRepo.GetUserByID(userID)
+	} else {
+		user, err = s.linkOrCreateOIDCUser(identity)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.userService.EnsureCanLogin(user); err != nil {
+		return nil, nil, err
+	}
+
+	return s.completeLogin(user, client)
+}
+
+// linkOrCreateOIDCUser links an external identity to the user with the same verified email,
+// or creates a user with the default role for it
+func (s *AuthService) linkOrCreateOIDCUser(identity *oidc.Identity) (*userModel.User, error) {
+	if identity.Email == "" {
+		return nil, errors.ErrAuthOIDCNoEmail
+	}
+
+	existingUser, err := s.userRepo.GetUserByEmail(identity.Email)
+	if err != nil && !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existingUser != nil {
+		// Only link when the provider and the account both verified the email. Anyone can register
+		// an unverified account with someone else's email, linking it would let them into the owner's login
+		if !identity.EmailVerified || !existingUser.IsEmailVerified() {
+			return nil, errors.ErrAuthOIDCConflict
+		}
+		if err := s.oidcService.LinkIdentity(database.DB, existingUser.ID, identity); err != nil {
+			return nil, err
+		}
+		return existingUser, nil
+	}
+
+	base := identity.Name
+	if base == "" {
+		base = strings.Split(identity.Email, "@")[0]
+	}
+	username, err := userService.GenerateHandle(s.userRepo, base)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser := &userModel.User{
+		Username: &username,
+		Email:    identity.Email,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		newUser.EmailVerifiedAt = &now
+	}
+
+	// New users get the default role, so the user, the role and the link are written with the policy change
+	var user *userModel.User
+	err = s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		user, err = s.userRepo.WithTx(tx).CreateUser(newUser)
 		if err != nil {
+			return userService.HandleConflictError(err)
+		}
+
+		if err := s.userRoleService.AssignDefaultRoleToNewUser(tx, casbin, user.ID); err != nil {
 			return err
 		}
+		return s.oidcService.LinkIdentity(tx, user.ID, identity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
-		// 4. Get default "user" role
-		defaultRole, err := s.roleRepo.WithTx(tx).GetByName("user")
+// completeLogin issues tokens for an authenticated user, or an MFA challenge if
+// the user has two-factor authentication enabled
+func (s *AuthService) completeLogin(user *userModel.User, client authService.ClientInfo) (*userDto.AuthResponseDTO, *dto.MFAChallengeDTO, error) {
+	mfaEnabled, err := s.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mfaEnabled {
+		mfaToken, err := utils.GenerateMFAPendingToken(user.ID)
 		if err != nil {
-			return errors.ErrRoleNotFound
+			return nil, nil, err
 		}
 
-		// 5. Assign role in database (user_roles table)
-		if err := s.userRepo.WithTx(tx).AssignRoleToUser(userCreated.ID, defaultRole.ID); err != nil {
+		return nil, &dto.MFAChallengeDTO{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(utils.MFAPendingExpiration.Seconds()),
+		}, nil
+	}
+
+	response, err := s.IssueTokens(user, client)
+	return response, nil, err
+}
+
+// VerifyMFALogin completes a two-step login by exchanging an MFA token and a valid code for tokens
+func (s *AuthService) VerifyMFALogin(mfaToken, code string, client authService.ClientInfo) (*userDto.AuthResponseDTO, error) {
+	claims, err := utils.ValidateMFAPendingToken(mfaToken)
+	if err != nil || s.tokenService.IsRevoked(claims) {
+		return nil, errors.ErrAuthInvalidToken
+	}
+
+	if err := s.throttle.CheckMFA(claims.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.Verify(claims.UserID, code); err != nil {
+		if stdErrors.Is(err, errors.ErrAuthMFAInvalidCode) {
+			if recordErr := s.throttle.RecordMFAFailure(claims.UserID); recordErr != nil {
+				log.Printf("Failed to record MFA failure of user %d: %v", claims.UserID, recordErr)
+			}
+		}
+		return nil, err
+	}
+
+	// The token completes a single login, a copy of it can't be exchanged again
+	if err := s.tokenService.ConsumeMFAPendingToken(claims); err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle.RecordMFASuccess(claims.UserID); err != nil {
+		log.Printf("Failed to reset MFA failures of user %d: %v", claims.UserID, err)
+	}
+
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, errors.ErrAuthInvalidToken
+	}
+
+	if err := s.userService.EnsureCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	return s.IssueTokens(user, client)
+}
+
+// EnrollMFA starts a TOTP enrollment for the user, labelled with their email in authenticator apps
+func (s *AuthService) EnrollMFA(userID uint) (*dto.MFAEnrollResponseDTO, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	secret, uri, err := s.mfaService.Enroll(user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponseDTO{Secret: secret, OTPAuthURI: uri}, nil
+}
+
+// Refresh rotates a refresh token and issues a new access/refresh token pair
+// Reusing an already rotated refresh token revokes its whole token family
+func (s *AuthService) Refresh(refreshToken string, client authService.ClientInfo) (*userDto.AuthResponseDTO, error) {
+	newRefreshToken, userID, sessionID, err := s.tokenService.RotateRefreshToken(refreshToken, client)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.ErrAuthRefreshInvalid
+	}
+
+	if err := s.userService.EnsureCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, usernameOf(user), sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAuthResponse(user, accessToken, newRefreshToken), nil
+}
+
+// Logout revokes the current access token and session and, if given, the refresh token family
+func (s *AuthService) Logout(claims *utils.JWTClaims, refreshToken string) error {
+	if err := s.tokenService.RevokeAccessToken(claims); err != nil {
+		return err
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.tokenService.RevokeSession(claims.UserID, claims.SessionID); err != nil && !stdErrors.Is(err, errors.ErrNotFound) {
 			return err
 		}
+	}
+
+	if refreshToken != "" {
+		return s.tokenService.RevokeRefreshToken(claims.UserID, refreshToken)
+	}
+	return nil
+}
 
-		// 6. Assign role in Casbin (for authorization checks)
-		// Note: This is done within the transaction context
-		if err := s.casbinService.AssignRoleToUser(userCreated.ID, defaultRole.Name); err != nil {
+// LogoutAll revokes every access and refresh token of the user ("logout everywhere")
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.tokenService.RevokeAllForUser(userID)
+}
+
+// recentLoginWindow is how long after logging in a user without a password can change
+// their credentials without entering a two-factor code
+const recentLoginWindow = 10 * time.Minute
+
+// ChangePassword sets a new password for a logged in user
+// This is a cross-domain operation that:
+// 1. Confirms the user's identity and stores the new hash (User domain)
+// 2. Signs out every other session, keeping the one that made the change (Auth domain)
+func (s *AuthService) ChangePassword(userID, currentSessionID uint, currentPassword, mfaCode, newPassword, ip string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+
+	if err := s.confirmIdentity(user, currentSessionID, currentPassword, mfaCode, ip); err != nil {
+		return err
+	}
+	if err := s.userService.SetPassword(user.ID, newPassword); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeOtherSessions(userID, currentSessionID)
+}
+
+// RequestEmailChange mails a confirmation link to the new address after confirming the user's identity
+// The account keeps its current email until the link is opened (see VerifyEmail)
+func (s *AuthService) RequestEmailChange(userID, currentSessionID uint, newEmail, currentPassword, mfaCode, ip string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+
+	if err := s.confirmIdentity(user, currentSessionID, currentPassword, mfaCode, ip); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "The new email address is the same as the current one")
+	}
+	if existingUser, _ := s.userRepo.GetUserByEmail(newEmail); existingUser != nil {
+		return errors.ErrUserEmailTaken
+	}
+
+	return s.verifyService.SendEmailChange(user.ID, newEmail)
+}
+
+// confirmIdentity re-authenticates a user before a credential change
+// Users with a password confirm it. Users who only log in through an external provider confirm
+// a second factor, or make the change from a session they logged in to within recentLoginWindow.
+// Wrong passwords and codes count towards the same lockouts as Login and VerifyMFALogin, so a stolen
+// session can't be used to guess them
+func (s *AuthService) confirmIdentity(user *userModel.User, sessionID uint, currentPassword, mfaCode, ip string) error {
+	if user.Password != nil {
+		return s.confirmPassword(user, currentPassword, ip)
+	}
+
+	if mfaCode != "" {
+		return s.confirmMFACode(user.ID, mfaCode)
+	}
+
+	recent, err := s.tokenService.IsRecentLogin(user.ID, sessionID, recentLoginWindow)
+	if err != nil {
+		return err
+	}
+	if !recent {
+		return errors.ErrAuthReauthRequired
+	}
+	return nil
+}
+
+// confirmPassword checks the current password of the user, throttled like Login
+func (s *AuthService) confirmPassword(user *userModel.User, password, ip string) error {
+	if err := s.throttle.CheckLogin(user.Email, ip); err != nil {
+		return err
+	}
+
+	if err := s.userService.CheckPassword(user, password); err != nil {
+		if stdErrors.Is(err, errors.ErrUserWrongPassword) {
+			if recordErr := s.throttle.RecordLoginFailure(user.Email, ip); recordErr != nil {
+				log.Printf("Failed to record password failure of user %d: %v", user.ID, recordErr)
+			}
+		}
+		return err
+	}
+
+	if err := s.throttle.RecordLoginSuccess(user.Email); err != nil {
+		log.Printf("Failed to reset login failures of user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// confirmMFACode checks a second-factor code of the user, throttled like VerifyMFALogin
+func (s *AuthService) confirmMFACode(userID uint, code string) error {
+	if err := s.throttle.CheckMFA(userID); err != nil {
+		return err
+	}
+
+	if err := s.mfaService.Verify(userID, code); err != nil {
+		if stdErrors.Is(err, errors.ErrAuthMFAInvalidCode) {
+			if recordErr := s.throttle.RecordMFAFailure(userID); recordErr != nil {
+				log.Printf("Failed to record MFA failure of user %d: %v", userID, recordErr)
+			}
+		}
+		return err
+	}
+
+	if err := s.throttle.RecordMFASuccess(userID); err != nil {
+		log.Printf("Failed to reset MFA failures of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// ForgotPassword mails a password reset link if an account with the email exists
+// It never reports whether the email is registered; the lookup and the email are
+// handled by the reset worker so the response time doesn't tell either.
+// Requests are throttled per email and per IP address like failed logins
+func (s *AuthService) ForgotPassword(email, ip string) error {
+	if err := s.throttle.CheckPasswordReset(email, ip); err != nil {
+		return err
+	}
+	if err := s.throttle.RecordPasswordReset(email, ip); err != nil {
+		return err
+	}
+
+	select {
+	case s.resetRequests <- email:
+	default:
+		log.Printf("Password reset queue is full, dropped a request")
+	}
+	return nil
+}
+
+// sendPasswordResets mails the queued password reset requests one at a time
+func (s *AuthService) sendPasswordResets() {
+	for email := range s.resetRequests {
+		user, err := s.userRepo.GetUserByEmail(email)
+		if err != nil {
+			continue
+		}
+
+		if err := s.resetService.SendReset(user.ID, user.Email); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}
+}
+
+// ResetPassword sets a new password using a reset token
+// This is a cross-domain operation that:
+// 1. Consumes the single-use reset token (Auth domain)
+// 2. Updates the password hash (User domain)
+// 3. Revokes every existing session of the user (Auth domain)
+func (s *AuthService) ResetPassword(plainToken, newPassword string) error {
+	if err := utils.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	var userID uint
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := s.resetService.ConsumeToken(tx, plainToken)
+		if err != nil {
 			return err
 		}
 
+		hashedPassword, err := utils.HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		if err := s.userRepo.WithTx(tx).UpdatePassword(token.UserID, hashedPassword); err != nil {
+			return err
+		}
+
+		userID = token.UserID
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeAllForUser(userID)
+}
 
+// ListSessions lists the active login sessions of the user, marking the one the request came from
+func (s *AuthService) ListSessions(userID, currentSessionID uint) ([]dto.SessionDTO, error) {
+	sessions, err := s.tokenService.ListSessions(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return userCreated, nil
+	result := make([]dto.SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, dto.SessionDTO{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+// RevokeSession signs the user out of one of their login sessions
+func (s *AuthService) RevokeSession(userID, sessionID uint) error {
+	return s.tokenService.RevokeSession(userID, sessionID)
+}
+
+// UnlockAccount lifts the login lockout of a user and, if given, of an IP address
+func (s *AuthService) UnlockAccount(userID uint, ip string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+
+	return s.throttle.Unlock(user.ID, user.Email, ip)
+}
+
+// IssueTokens starts a login session and issues a fresh access token and a refresh token for a user
+func (s *AuthService) IssueTokens(user *userModel.User, client authService.ClientInfo) (*userDto.AuthResponseDTO, error) {
+	refreshToken, sessionID, err := s.tokenService.StartSession(user.ID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, usernameOf(user), sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAuthResponse(user, accessToken, refreshToken), nil
+}
+
+// RegistrationResponse issues tokens for a newly registered user
+// Users who must verify their email first only get their account back, they log in once verified
+func (s *AuthService) RegistrationResponse(user *userModel.User, client authService.ClientInfo) (*userDto.AuthResponseDTO, error) {
+	if err := s.userService.EnsureCanLogin(user); err != nil {
+		if stdErrors.Is(err, errors.ErrUserEmailNotVerified) {
+			return &userDto.AuthResponseDTO{User: buildUserResponse(user)}, nil
+		}
+		return nil, err
+	}
+	return s.IssueTokens(user, client)
+}
+
+func usernameOf(user *userModel.User) string {
+	if user.Username != nil {
+		return *user.Username
+	}
+	return ""
+}
+
+func buildAuthResponse(user *userModel.User, accessToken, refreshToken string) *userDto.AuthResponseDTO {
+	return &userDto.AuthResponseDTO{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.TokenExpiration.Seconds()),
+		User:         buildUserResponse(user),
+	}
+}
+
+func buildUserResponse(user *userModel.User) userDto.UserResponseDTO {
+	return userDto.UserResponseDTO{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		AvatarURL:     user.AvatarURL,
+		Bio:           user.Bio,
+		Status:        user.Status,
+	}
 }
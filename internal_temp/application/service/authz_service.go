@@ -0,0 +1,195 @@
+package service
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	commonModel "github.com/FeisalDy/nogo/internal/common/model"
+	commonRepo "github.com/FeisalDy/nogo/internal/common/repository"
+	userModel "github.com/FeisalDy/nogo/internal/user/model"
+	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	"gorm.io/gorm"
+)
+
+// AuthzService explains authorization decisions to admins
+// It reproduces what the middleware checks: the Casbin policies, the account status,
+// and for "own" scoped actions, the ownership of the resource
+type AuthzService struct {
+	userRepo      *userRepo.UserRepository
+	auditRepo     *commonRepo.AuthzAuditRepository
+	casbinService *casbinService.CasbinService
+}
+
+// NewAuthzService creates a new instance of AuthzService
+func NewAuthzService(
+	userRepository *userRepo.UserRepository,
+	auditRepository *commonRepo.AuthzAuditRepository,
+	casbin *casbinService.CasbinService,
+) *AuthzService {
+	return &AuthzService{
+		userRepo:      userRepository,
+		auditRepo:     auditRepository,
+		casbinService: casbin,
+	}
+}
+
+// Explain explains whether a user may perform an action on a resource
+func (s *AuthzService) Explain(req dto.AuthzExplainQueryDTO) (*dto.AuthzExplanationDTO, error) {
+	user, err := s.getUser(req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &dto.AuthzExplanationDTO{
+		UserID:   user.ID,
+		Resource: req.Resource,
+		Action:   req.Action,
+	}
+	if req.NovelID != 0 {
+		explanation.Domain = casbinService.FormatNovelDomain(req.NovelID)
+	}
+
+	status := user.EffectiveStatus(time.Now())
+	statusOK := status == userModel.StatusActive
+	explanation.Conditions = append(explanation.Conditions, dto.AuthzConditionDTO{
+		Name:   "account_status",
+		Passed: &statusOK,
+		Detail: "account is " + status,
+	})
+
+	if !casbinService.IsOwnershipScoped(req.Resource, req.Action) {
+		check, err := s.casbinService.Explain(user.ID, explanation.Domain, req.Resource, req.Action)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Checks = []*casbinService.Explanation{check}
+		explanation.Allowed = statusOK && check.Allowed
+		return explanation, nil
+	}
+
+	anyCheck, err := s.casbinService.Explain(user.ID, explanation.Domain, req.Resource, casbinService.AnyAction(req.Action))
+	if err != nil {
+		return nil, err
+	}
+	ownCheck, err := s.casbinService.Explain(user.ID, explanation.Domain, req.Resource, casbinService.OwnAction(req.Action))
+	if err != nil {
+		return nil, err
+	}
+	explanation.Checks = []*casbinService.Explanation{anyCheck, ownCheck}
+
+	ownership, err := s.checkOwnership(user.ID, req.Resource, req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Conditions = append(explanation.Conditions, ownership)
+
+	// Permission on any resource implies permission on your own, as in OwnershipMiddleware
+	owned := ownership.Passed != nil && *ownership.Passed
+	explanation.Allowed = statusOK && (anyCheck.Allowed || (ownCheck.Allowed && owned))
+	return explanation, nil
+}
+
+// GetRoutes lists every route with the access it requires and the roles holding its permission
+func (s *AuthzService) GetRoutes() ([]dto.AuthzRouteDTO, error) {
+	permissions := casbinService.RoutePermissions()
+	routes := make([]dto.AuthzRouteDTO, 0, len(permissions))
+	for _, permission := range permissions {
+		route := dto.AuthzRouteDTO{RoutePermission: permission}
+		if permission.Resource != "" {
+			roles, err := s.casbinService.GetRolesWithRoutePermission(permission)
+			if err != nil {
+				return nil, err
+			}
+			route.Roles = roles
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// defaultAuditPageSize is the number of audit entries listed when no limit is given
+const defaultAuditPageSize = 20
+
+// GetAuditLog lists a page of the authz audit log, newest first
+// The default page size is set on req when it has no limit
+func (s *AuthzService) GetAuditLog(req *dto.AuthzAuditQueryDTO) ([]commonModel.AuthzAudit, *dto.AuthzAuditPageInfoDTO, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultAuditPageSize
+	}
+
+	entries, hasMore, err := s.auditRepo.List(commonRepo.AuthzAuditFilter{
+		ActorID:   req.ActorID,
+		Action:    req.Action,
+		Target:    req.Target,
+		RequestID: req.RequestID,
+		Since:     req.Since,
+		Until:     req.Until,
+		Cursor:    req.Cursor,
+		Limit:     req.Limit,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pageInfo := &dto.AuthzAuditPageInfoDTO{HasMore: hasMore}
+	if hasMore {
+		pageInfo.NextCursor = &entries[len(entries)-1].ID
+	}
+	return entries, pageInfo, nil
+}
+
+// checkOwnership checks whether the user owns the resource, using the loader registered by OwnershipMiddleware
+func (s *AuthzService) checkOwnership(userID uint, resource string, resourceID uint) (dto.AuthzConditionDTO, error) {
+	condition := dto.AuthzConditionDTO{Name: "ownership"}
+
+	loader, ok := casbinService.GetOwnerLoader(resource)
+	if !ok {
+		condition.Detail = "no owner loader is registered for " + resource
+		return condition, nil
+	}
+	if resourceID == 0 {
+		condition.Detail = "resource_id not given, only the \"any\" scope applies"
+		return condition, nil
+	}
+
+	ownerIDs, err := loader(resourceID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return condition, errors.ErrNotFound
+		}
+		return condition, err
+	}
+
+	owned := slices.Contains(ownerIDs, userID)
+	condition.Passed = &owned
+	if owned {
+		condition.Detail = fmt.Sprintf("user %d owns %s %d", userID, resource, resourceID)
+	} else {
+		condition.Detail = fmt.Sprintf("user %d does not own %s %d", userID, resource, resourceID)
+	}
+	return condition, nil
+}
+
+// getUser gets a user by ID or email
+func (s *AuthzService) getUser(user string) (*userModel.User, error) {
+	var found *userModel.User
+	var err error
+	if id, parseErr := strconv.ParseUint(user, 10, 32); parseErr == nil {
+		found, err = s.userRepo.GetUserByID(uint(id))
+	} else {
+		found, err = s.userRepo.GetUserByEmail(user)
+	}
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
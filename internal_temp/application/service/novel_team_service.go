@@ -0,0 +1,286 @@
+package service
+
+import (
+	stdErrors "errors"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	roleModel "github.com/FeisalDy/nogo/internal/role/model"
+	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
+	teamModel "github.com/FeisalDy/nogo/internal/team/model"
+	teamRepo "github.com/FeisalDy/nogo/internal/team/repository"
+	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	"gorm.io/gorm"
+)
+
+// NovelTeamService manages per-novel teams
+// A team role is stored in novel_team_members and mirrored as a Casbin role in the novel's domain,
+// so a user can be an editor on one novel and a regular user everywhere else
+type NovelTeamService struct {
+	teamRepo      *teamRepo.TeamMemberRepository
+	userRepo      *userRepo.UserRepository
+	roleRepo      *roleRepo.RoleRepository
+	casbinService *casbinService.CasbinService
+}
+
+// NewNovelTeamService creates a new instance of NovelTeamService
+func NewNovelTeamService(
+	teamRepository *teamRepo.TeamMemberRepository,
+	userRepository *userRepo.UserRepository,
+	roleRepository *roleRepo.RoleRepository,
+	casbin *casbinService.CasbinService,
+) *NovelTeamService {
+	return &NovelTeamService{
+		teamRepo:      teamRepository,
+		userRepo:      userRepository,
+		roleRepo:      roleRepository,
+		casbinService: casbin,
+	}
+}
+
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *NovelTeamService) WithActor(actor casbinService.AuditActor) *NovelTeamService {
+	service := *s
+	service.casbinService = s.casbinService.WithActor(actor)
+	return &service
+}
+
+// GetTeam lists the members and pending invitations of a novel team
+func (s *NovelTeamService) GetTeam(novelID uint) (*dto.NovelTeamDTO, error) {
+	if err := s.ensureNovelExists(novelID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.teamRepo.GetByNovelID(novelID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	roleNames := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		roleNames[role.ID] = role.Name
+	}
+
+	team := &dto.NovelTeamDTO{
+		NovelID:   novelID,
+		TeamRoles: casbinService.TeamRoles(),
+		Members:   make([]dto.TeamMemberDTO, 0, len(members)),
+	}
+	for _, member := range members {
+		memberDTO := dto.TeamMemberDTO{
+			UserID:     member.UserID,
+			Role:       roleNames[member.RoleID],
+			Status:     member.Status,
+			InvitedBy:  member.InvitedBy,
+			AcceptedAt: member.AcceptedAt,
+			CreatedAt:  member.CreatedAt,
+		}
+		if user, err := s.userRepo.GetUserByID(member.UserID); err == nil {
+			memberDTO.Username = user.Username
+		}
+		team.Members = append(team.Members, memberDTO)
+	}
+	return team, nil
+}
+
+// Invite invites a user to a novel team with a team role
+// The role only takes effect once the user accepts the invitation
+func (s *NovelTeamService) Invite(novelID, invitedBy uint, req dto.InviteTeamMemberDTO) error {
+	if err := s.ensureNovelExists(novelID); err != nil {
+		return err
+	}
+	if err := s.ensureUserExists(req.UserID); err != nil {
+		return err
+	}
+	role, err := s.getTeamRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.teamRepo.GetByNovelAndUser(novelID, req.UserID); err == nil {
+		return errors.ErrTeamAlreadyMember
+	} else if !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.teamRepo.Create(&teamModel.NovelTeamMember{
+		NovelID:   novelID,
+		UserID:    req.UserID,
+		RoleID:    role.ID,
+		Status:    teamModel.MemberStatusInvited,
+		InvitedBy: &invitedBy,
+	})
+}
+
+// AcceptInvitation joins the team the user was invited to and grants the team role
+func (s *NovelTeamService) AcceptInvitation(novelID, userID uint) error {
+	member, err := s.getMember(novelID, userID)
+	if err != nil {
+		return err
+	}
+	if member.IsActive() {
+		return errors.ErrTeamMemberNotFound
+	}
+
+	role, err := s.roleRepo.GetByID(member.RoleID)
+	if err != nil {
+		return err
+	}
+
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		if err := s.teamRepo.WithTx(tx).Activate(member.ID, time.Now()); err != nil {
+			return err
+		}
+		return casbin.AssignRoleInDomain(userID, role.Name, casbinService.FormatNovelDomain(novelID))
+	})
+}
+
+// DeclineInvitation deletes the user's pending invitation
+func (s *NovelTeamService) DeclineInvitation(novelID, userID uint) error {
+	member, err := s.getMember(novelID, userID)
+	if err != nil {
+		return err
+	}
+	if member.IsActive() {
+		return errors.ErrTeamMemberNotFound
+	}
+	return s.teamRepo.Delete(member.ID)
+}
+
+// AssignRole sets a user's team role directly, without an invitation
+// An existing member or invitation gets the new role, and is activated
+func (s *NovelTeamService) AssignRole(novelID, userID, assignedBy uint, req dto.AssignTeamRoleDTO) error {
+	if err := s.ensureNovelExists(novelID); err != nil {
+		return err
+	}
+	if err := s.ensureUserExists(userID); err != nil {
+		return err
+	}
+	role, err := s.getTeamRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.teamRepo.GetByNovelAndUser(novelID, userID)
+	if err != nil && !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	domain := casbinService.FormatNovelDomain(novelID)
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		repo := s.teamRepo.WithTx(tx)
+
+		if member == nil {
+			now := time.Now()
+			if err := repo.Create(&teamModel.NovelTeamMember{
+				NovelID:    novelID,
+				UserID:     userID,
+				RoleID:     role.ID,
+				Status:     teamModel.MemberStatusActive,
+				InvitedBy:  &assignedBy,
+				AcceptedAt: &now,
+			}); err != nil {
+				return err
+			}
+			return casbin.AssignRoleInDomain(userID, role.Name, domain)
+		}
+
+		if member.IsActive() && member.RoleID != role.ID {
+			if err := s.removeDomainRole(casbin, member, domain); err != nil {
+				return err
+			}
+		}
+		if err := repo.UpdateRole(member.ID, role.ID); err != nil {
+			return err
+		}
+		if !member.IsActive() {
+			if err := repo.Activate(member.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+		return casbin.AssignRoleInDomain(userID, role.Name, domain)
+	})
+}
+
+// RemoveMember removes a member or pending invitation from a novel team and revokes the team role
+func (s *NovelTeamService) RemoveMember(novelID, userID uint) error {
+	member, err := s.getMember(novelID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		if err := s.teamRepo.WithTx(tx).Delete(member.ID); err != nil {
+			return err
+		}
+		if !member.IsActive() {
+			return nil
+		}
+		return s.removeDomainRole(casbin, member, casbinService.FormatNovelDomain(novelID))
+	})
+}
+
+// removeDomainRole revokes a member's team role, casbin comes from CasbinService.Transaction
+func (s *NovelTeamService) removeDomainRole(casbin *casbinService.CasbinService, member *teamModel.NovelTeamMember, domain string) error {
+	role, err := s.roleRepo.GetByID(member.RoleID)
+	if err != nil {
+		return err
+	}
+	return casbin.RemoveRoleInDomain(member.UserID, role.Name, domain)
+}
+
+func (s *NovelTeamService) getMember(novelID, userID uint) (*teamModel.NovelTeamMember, error) {
+	member, err := s.teamRepo.GetByNovelAndUser(novelID, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrTeamMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// getTeamRole gets a role that can be assigned within a team
+func (s *NovelTeamService) getTeamRole(roleName string) (*roleModel.Role, error) {
+	if !casbinService.IsTeamRole(roleName) {
+		return nil, errors.NewAppError(errors.ErrCodeTeamRoleInvalid, "Role can't be assigned within a team").WithDetails(map[string]any{
+			"team_roles": casbinService.TeamRoles(),
+		})
+	}
+
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *NovelTeamService) ensureNovelExists(novelID uint) error {
+	exists, err := s.teamRepo.NovelExists(novelID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.ErrTeamNovelNotFound
+	}
+	return nil
+}
+
+func (s *NovelTeamService) ensureUserExists(userID uint) error {
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
+	"gorm.io/gorm"
+)
+
+// PolicyService keeps the role permissions and role inheritance in Casbin in line with the policy file
+type PolicyService struct {
+	roleRepo      *roleRepo.RoleRepository
+	casbinService *casbinService.CasbinService
+}
+
+// NewPolicyService creates a new instance of PolicyService
+func NewPolicyService(roleRepository *roleRepo.RoleRepository, casbin *casbinService.CasbinService) *PolicyService {
+	return &PolicyService{
+		roleRepo:      roleRepository,
+		casbinService: casbin,
+	}
+}
+
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *PolicyService) WithActor(actor casbinService.AuditActor) *PolicyService {
+	service := *s
+	service.casbinService = s.casbinService.WithActor(actor)
+	return &service
+}
+
+// Plan compares the policy file with the rules in the casbin_rule table
+func (s *PolicyService) Plan(path string) (*dto.PolicyPlanDTO, error) {
+	file, err := casbinService.LoadPolicyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare with the table rather than what this process loaded earlier
+	if err := s.casbinService.ReloadPolicies(); err != nil {
+		return nil, err
+	}
+	diff, err := s.casbinService.DiffPolicyFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		existing[role.Name] = true
+	}
+
+	plan := &dto.PolicyPlanDTO{
+		File:         path,
+		Diff:         diff,
+		UnknownRoles: []string{},
+	}
+	for _, role := range file.Roles() {
+		if !existing[role] {
+			plan.UnknownRoles = append(plan.UnknownRoles, role)
+		}
+	}
+	return plan, nil
+}
+
+// Apply applies a plan in a single transaction, so either every change is made or none
+func (s *PolicyService) Apply(plan *dto.PolicyPlanDTO) error {
+	if plan.Diff.IsEmpty() {
+		return nil
+	}
+	return s.casbinService.Transaction(func(_ *gorm.DB, casbin *casbinService.CasbinService) error {
+		return casbin.ApplyPolicyDiff(plan.Diff)
+	})
+}
@@ -3,11 +3,14 @@ package service
 import (
 	"strings"
 
+	"github.com/FeisalDy/nogo/internal/application/dto"
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
+	mediaRepo "github.com/FeisalDy/nogo/internal/media/repository"
 	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
 	userDto "github.com/FeisalDy/nogo/internal/user/dto"
 	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
 )
 
 // UserProfileService handles user profile operations that span multiple domains
@@ -15,6 +18,7 @@ import (
 type UserProfileService struct {
 	userRepo      *userRepo.UserRepository
 	roleRepo      *roleRepo.RoleRepository
+	mediaRepo     *mediaRepo.MediaRepository
 	casbinService *casbinService.CasbinService
 }
 
@@ -22,11 +26,13 @@ type UserProfileService struct {
 func NewUserProfileService(
 	userRepository *userRepo.UserRepository,
 	roleRepository *roleRepo.RoleRepository,
+	mediaRepository *mediaRepo.MediaRepository,
 	casbin *casbinService.CasbinService,
 ) *UserProfileService {
 	return &UserProfileService{
 		userRepo:      userRepository,
 		roleRepo:      roleRepository,
+		mediaRepo:     mediaRepository,
 		casbinService: casbin,
 	}
 }
@@ -36,7 +42,7 @@ func NewUserProfileService(
 // 1. Gets user from User domain
 // 2. Gets role IDs from user_roles table
 // 3. Gets role details from Role domain
-// 4. Gets permissions from Casbin (authorization domain)
+// 4. Gets effective permissions from Casbin, including those inherited from parent roles
 func (s *UserProfileService) GetUserWithPermissions(userID uint) (*userDto.UserWithPermissionsDTO, error) {
 	// 1. Get user from User domain
 	user, err := s.userRepo.GetUserByID(userID)
@@ -71,49 +77,90 @@ func (s *UserProfileService) GetUserWithPermissions(userID uint) (*userDto.UserW
 		roleNames = append(roleNames, role.Name)
 	}
 
-	// 4. Get permissions from Casbin for each role
-	permissionMap := make(map[string]bool) // Use map to avoid duplicates
-	for _, roleName := range roleNames {
-		permissions, err := s.casbinService.GetPermissionsForRole(roleName)
-		if err != nil {
-			// Log error but continue - don't fail completely
-			continue
+	// 4. Get the effective permissions from Casbin, including those inherited through parent roles
+	permissions, err := s.casbinService.GetEffectivePermissions(roleNames)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionDTOs := make([]userDto.PermissionDTO, 0, len(permissions))
+	for _, perm := range permissions {
+		permissionDTOs = append(permissionDTOs, userDto.PermissionDTO{
+			Resource: perm.Resource,
+			Action:   perm.Action,
+			Source:   perm.Source,
+		})
+	}
+
+	// Build response DTO
+	response := &userDto.UserWithPermissionsDTO{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		AvatarMediaID: user.AvatarMediaID,
+		AvatarURL:     user.AvatarURL,
+		Bio:           user.Bio,
+		Status:        user.Status,
+		Roles:         roleDTOs,
+		Permissions:   permissionDTOs,
+	}
+
+	return response, nil
+}
+
+// UpdateProfile applies a partial update to the user's username, bio and avatar
+// This is a cross-domain operation that:
+// 1. Checks the avatar is an image the user uploaded (Media domain)
+// 2. Updates the profile, keeping avatar_url in sync with the avatar media (User domain)
+// 3. Returns the updated profile with roles and permissions
+func (s *UserProfileService) UpdateProfile(userID uint, updateDTO *dto.UpdateProfileDTO) (*userDto.UserWithPermissionsDTO, error) {
+	updates := make(map[string]any)
+
+	if updateDTO.Username != nil {
+		username := strings.TrimSpace(*updateDTO.Username)
+		if err := userService.EnsureHandleAvailable(s.userRepo, username, userID); err != nil {
+			return nil, err
 		}
+		updates["username"] = username
+	}
 
-		// permissions format: [[role, resource, action], ...]
-		for _, perm := range permissions {
-			if len(perm) >= 3 {
-				// Create unique key: "resource:action"
-				key := perm[1] + ":" + perm[2]
-				permissionMap[key] = true
-			}
+	if updateDTO.Bio != nil {
+		if bio := strings.TrimSpace(*updateDTO.Bio); bio != "" {
+			updates["bio"] = bio
+		} else {
+			updates["bio"] = nil
 		}
 	}
 
-	// Convert permission map to DTO array
-	permissionDTOs := make([]userDto.PermissionDTO, 0, len(permissionMap))
-	for key := range permissionMap {
-		// Split key back to resource:action
-		parts := strings.Split(key, ":")
-		if len(parts) == 2 {
-			permissionDTOs = append(permissionDTOs, userDto.PermissionDTO{
-				Resource: parts[0],
-				Action:   parts[1],
-			})
+	if updateDTO.AvatarMediaID != nil {
+		if *updateDTO.AvatarMediaID == 0 {
+			updates["avatar_media_id"] = nil
+			updates["avatar_url"] = nil
+		} else {
+			media, err := s.mediaRepo.GetByID(*updateDTO.AvatarMediaID)
+			if err != nil {
+				return nil, errors.ErrUserAvatarInvalid
+			}
+			if media.UploadBy == nil || *media.UploadBy != userID {
+				return nil, errors.ErrUserAvatarInvalid
+			}
+			if media.MimeType != nil && !strings.HasPrefix(*media.MimeType, "image/") {
+				return nil, errors.ErrUserAvatarInvalid
+			}
+			updates["avatar_media_id"] = media.ID
+			updates["avatar_url"] = media.URL
 		}
 	}
 
-	// Build response DTO
-	response := &userDto.UserWithPermissionsDTO{
-		ID:          user.ID,
-		Username:    user.Username,
-		Email:       user.Email,
-		AvatarURL:   user.AvatarURL,
-		Bio:         user.Bio,
-		Status:      user.Status,
-		Roles:       roleDTOs,
-		Permissions: permissionDTOs,
+	if len(updates) > 0 {
+		if err := s.userRepo.UpdateProfile(userID, updates); err != nil {
+			if userRepo.IsHandleConflict(err) {
+				return nil, errors.ErrUserHandleTaken
+			}
+			return nil, errors.ErrUserUpdateFailed
+		}
 	}
 
-	return response, nil
+	return s.GetUserWithPermissions(userID)
 }
@@ -1,9 +1,15 @@
 package service
 
 import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
-	"github.com/FeisalDy/nogo/internal/database"
+	roleModel "github.com/FeisalDy/nogo/internal/role/model"
 	roleRepo "github.com/FeisalDy/nogo/internal/role/repository"
 	userRepo "github.com/FeisalDy/nogo/internal/user/repository"
 	"gorm.io/gorm"
@@ -30,6 +36,13 @@ func NewUserRoleService(
 	}
 }
 
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *UserRoleService) WithActor(actor casbinService.AuditActor) *UserRoleService {
+	service := *s
+	service.casbinService = s.casbinService.WithActor(actor)
+	return &service
+}
+
 // AssignRoleToUser assigns a role to a user
 // This is a cross-domain operation that:
 // 1. Validates user exists (User domain)
@@ -37,8 +50,13 @@ func NewUserRoleService(
 // 3. Checks if user already has the role
 // 4. Creates the user-role relationship in database
 // 5. Syncs with Casbin for authorization
-func (s *UserRoleService) AssignRoleToUser(userID, roleID uint) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+// With expiresAt, the assignment is removed from both by the expiry sweeper once it has passed
+func (s *UserRoleService) AssignRoleToUser(userID, roleID uint, expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.ErrUserRoleExpiryInvalid
+	}
+
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
 		// 1. Validate user exists
 		user, err := s.userRepo.WithTx(tx).GetUserByID(userID)
 		if err != nil {
@@ -57,27 +75,8 @@ func (s *UserRoleService) AssignRoleToUser(userID, roleID uint) error {
 			return errors.ErrRoleNotFound
 		}
 
-		// 3. Check if user already has the role
-		hasRole, err := s.userRepo.WithTx(tx).HasRoleByID(userID, roleID)
-		if err != nil {
-			return err
-		}
-		if hasRole {
-			return errors.ErrUserAlreadyHasRole
-		}
-
-		// 4. Assign role in database (user_roles table)
-		if err := s.userRepo.WithTx(tx).AssignRoleToUser(userID, roleID); err != nil {
-			return err
-		}
-
-		// 5. Assign role in Casbin (for authorization checks)
-		// Note: Casbin operations are done outside transaction as it has its own handling
-		if err := s.casbinService.AssignRoleToUser(userID, role.Name); err != nil {
-			return err
-		}
-
-		return nil
+		// 3-5. Check the user doesn't have the role yet, and assign it in user_roles and Casbin
+		return s.assignRole(tx, casbin, userID, role, expiresAt)
 	})
 }
 
@@ -89,7 +88,7 @@ func (s *UserRoleService) AssignRoleToUser(userID, roleID uint) error {
 // 4. Removes the user-role relationship from database
 // 5. Syncs with Casbin for authorization
 func (s *UserRoleService) RemoveRoleFromUser(userID, roleID uint) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
 		// 1. Validate user exists
 		user, err := s.userRepo.WithTx(tx).GetUserByID(userID)
 		if err != nil {
@@ -122,8 +121,8 @@ func (s *UserRoleService) RemoveRoleFromUser(userID, roleID uint) error {
 			return err
 		}
 
-		// 5. Remove role from Casbin (for authorization checks)
-		if err := s.casbinService.RemoveRoleFromUser(userID, role.Name); err != nil {
+		// 5. Remove role from Casbin (for authorization checks), in the same transaction
+		if err := casbin.RemoveRoleFromUser(userID, role.Name); err != nil {
 			return err
 		}
 
@@ -133,35 +132,49 @@ func (s *UserRoleService) RemoveRoleFromUser(userID, roleID uint) error {
 
 // AssignDefaultRoleToNewUser assigns the default "user" role to a newly registered user
 // This is called during user registration to ensure all users have a default role
-func (s *UserRoleService) AssignDefaultRoleToNewUser(tx *gorm.DB, userID uint) error {
+// tx and casbin come from CasbinService.Transaction, so the Casbin rule is rolled back with the user_roles row
+func (s *UserRoleService) AssignDefaultRoleToNewUser(tx *gorm.DB, casbin *casbinService.CasbinService, userID uint) error {
 	// Get the default "user" role
 	defaultRole, err := s.roleRepo.WithTx(tx).GetByName("user")
 	if err != nil {
-		return err
-	}
-	if defaultRole == nil {
 		return errors.ErrRoleNotFound
 	}
 
-	// Assign role in database (user_roles table)
-	if err := s.userRepo.WithTx(tx).AssignRoleToUser(userID, defaultRole.ID); err != nil {
+	return s.assignRole(tx, casbin, userID, defaultRole, nil)
+}
+
+// assignRole assigns a role in the user_roles table and in Casbin (for authorization checks)
+// An expired assignment the sweeper hasn't removed yet counts as absent and is replaced. Its Casbin
+// rule grants the same role as the new assignment, so the rule is kept rather than removed and re-added
+// tx and casbin come from CasbinService.Transaction, so both are rolled back together
+func (s *UserRoleService) assignRole(tx *gorm.DB, casbin *casbinService.CasbinService, userID uint, role *roleModel.Role, expiresAt *time.Time) error {
+	repo := s.userRepo.WithTx(tx)
+
+	hasRole, err := repo.HasActiveRoleByID(userID, role.ID, time.Now())
+	if err != nil {
 		return err
 	}
+	if hasRole {
+		return errors.ErrUserAlreadyHasRole
+	}
 
-	// Assign role in Casbin (for authorization checks)
-	// Note: This is done outside the transaction as Casbin has its own transaction handling
-	if err := s.casbinService.AssignRoleToUser(userID, defaultRole.Name); err != nil {
+	// Drop the expired assignment, if any, before writing the new one
+	if err := repo.RemoveRoleFromUser(userID, role.ID); err != nil {
+		return err
+	}
+	if err := repo.AssignRoleToUser(userID, role.ID, expiresAt); err != nil {
 		return err
 	}
 
-	return nil
+	return casbin.AssignRoleToUser(userID, role.Name)
 }
 
 // GetUserRoles retrieves all roles assigned to a user
 // This is a cross-domain operation that:
 // 1. Validates user exists (User domain)
-// 2. Gets role IDs from user_roles table (via User repository)
+// 2. Gets role assignments from user_roles table (via User repository)
 // 3. Fetches role details from Role domain
+// Each role includes the assignment's expiry, which is null for permanent assignments
 func (s *UserRoleService) GetUserRoles(userID uint) ([]interface{}, error) {
 	// 1. Validate user exists (User domain)
 	user, err := s.userRepo.GetUserByID(userID)
@@ -172,16 +185,16 @@ func (s *UserRoleService) GetUserRoles(userID uint) ([]interface{}, error) {
 		return nil, errors.ErrUserNotFound
 	}
 
-	// 2. Get role IDs from user_roles table (stays in User domain boundary)
-	roleIDs, err := s.userRepo.GetUserRoleIDs(userID)
+	// 2. Get role assignments from user_roles table (stays in User domain boundary)
+	userRoles, err := s.userRepo.GetUserRoles(userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 3. Fetch role details from Role domain
-	result := make([]interface{}, 0, len(roleIDs))
-	for _, roleID := range roleIDs {
-		role, err := s.roleRepo.GetByID(roleID)
+	result := make([]interface{}, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		role, err := s.roleRepo.GetByID(userRole.RoleID)
 		if err != nil {
 			// Skip roles that no longer exist or have errors
 			continue
@@ -191,9 +204,190 @@ func (s *UserRoleService) GetUserRoles(userID uint) ([]interface{}, error) {
 				"id":          role.ID,
 				"name":        role.Name,
 				"description": role.Description,
+				"expires_at":  userRole.ExpiresAt,
 			})
 		}
 	}
 
 	return result, nil
 }
+
+// RemoveExpiredRoles removes the role assignments that have expired from user_roles and Casbin
+// Each one is removed in its own transaction, so one failure doesn't keep the others. An assignment
+// renewed after it was read as expired is left alone, along with its Casbin rule
+func (s *UserRoleService) RemoveExpiredRoles(now time.Time) (int, error) {
+	expired, err := s.userRepo.GetExpiredUserRoles(now)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, userRole := range expired {
+		role, err := s.roleRepo.GetByID(userRole.RoleID)
+		if err != nil {
+			log.Printf("Warning: Failed to load role %d of expired assignment for user %d: %v", userRole.RoleID, userRole.UserID, err)
+			continue
+		}
+
+		deleted := false
+		err = s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+			deleted, err = s.userRepo.WithTx(tx).RemoveExpiredRoleFromUser(userRole.UserID, userRole.RoleID, now)
+			if err != nil || !deleted {
+				return err
+			}
+			return casbin.RemoveRoleFromUser(userRole.UserID, role.Name)
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to remove expired role %s from user %d: %v", role.Name, userRole.UserID, err)
+			continue
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// StartExpirySweeper periodically removes expired role assignments in the background
+// An expired role keeps granting access until the next sweep, so the interval bounds how late it is revoked
+func (s *UserRoleService) StartExpirySweeper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			removed, err := s.RemoveExpiredRoles(time.Now())
+			if err != nil {
+				log.Printf("Warning: Failed to sweep expired roles: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("Removed %d expired role assignments", removed)
+			}
+		}
+	}()
+}
+
+// userRoleKey identifies a global role assignment on either side
+type userRoleKey struct {
+	userID   uint
+	roleName string
+}
+
+// Reconcile compares the user_roles table with the Casbin grouping rules of users and reports
+// the assignments found on only one side. With repair, each one is fixed in its own transaction
+// by trusting the source side, so one failure doesn't stop the others
+func (s *UserRoleService) Reconcile(source string, repair bool) (*dto.RoleReconcileReportDTO, error) {
+	if source != dto.RoleReconcileSourceDB && source != dto.RoleReconcileSourceCasbin {
+		return nil, fmt.Errorf("unknown reconcile source %q, expected %q or %q", source, dto.RoleReconcileSourceDB, dto.RoleReconcileSourceCasbin)
+	}
+
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	roleIDs := make(map[string]uint, len(roles))
+	roleNames := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		roleIDs[role.Name] = role.ID
+		roleNames[role.ID] = role.Name
+	}
+
+	userRoles, err := s.userRepo.GetAllUserRoles()
+	if err != nil {
+		return nil, err
+	}
+	casbinRoles, err := s.casbinService.GetAllUserRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	inCasbin := make(map[userRoleKey]bool)
+	for userID, names := range casbinRoles {
+		for _, name := range names {
+			inCasbin[userRoleKey{userID, name}] = true
+		}
+	}
+
+	report := &dto.RoleReconcileReportDTO{
+		Source: source,
+		Repair: repair,
+		Drift:  []dto.RoleDriftDTO{},
+	}
+
+	inDB := make(map[userRoleKey]bool, len(userRoles))
+	for _, userRole := range userRoles {
+		key := userRoleKey{userRole.UserID, roleNames[userRole.RoleID]}
+		inDB[key] = true
+		if key.roleName != "" && inCasbin[key] {
+			continue
+		}
+		report.Drift = append(report.Drift, dto.RoleDriftDTO{
+			Kind:     dto.RoleDriftMissingInCasbin,
+			UserID:   userRole.UserID,
+			RoleID:   userRole.RoleID,
+			RoleName: key.roleName,
+		})
+	}
+
+	var missingInDB []dto.RoleDriftDTO
+	for key := range inCasbin {
+		if inDB[key] {
+			continue
+		}
+		missingInDB = append(missingInDB, dto.RoleDriftDTO{
+			Kind:     dto.RoleDriftMissingInDB,
+			UserID:   key.userID,
+			RoleID:   roleIDs[key.roleName],
+			RoleName: key.roleName,
+		})
+	}
+	sort.Slice(missingInDB, func(i, j int) bool {
+		if missingInDB[i].UserID != missingInDB[j].UserID {
+			return missingInDB[i].UserID < missingInDB[j].UserID
+		}
+		return missingInDB[i].RoleName < missingInDB[j].RoleName
+	})
+	report.Drift = append(report.Drift, missingInDB...)
+
+	if !repair {
+		return report, nil
+	}
+
+	for i := range report.Drift {
+		drift := &report.Drift[i]
+		if err := s.repairDrift(drift, source); err != nil {
+			drift.Error = err.Error()
+			continue
+		}
+		drift.Repaired = true
+		report.Repaired++
+	}
+	return report, nil
+}
+
+// repairDrift makes the other side match the source side for one assignment
+func (s *UserRoleService) repairDrift(drift *dto.RoleDriftDTO, source string) error {
+	fromDB := source == dto.RoleReconcileSourceDB
+
+	// Adding the missing side needs the role to exist in the roles table, removing it doesn't
+	if fromDB && drift.RoleName == "" {
+		return fmt.Errorf("role %d doesn't exist", drift.RoleID)
+	}
+	if !fromDB && drift.RoleID == 0 {
+		return fmt.Errorf("role %q doesn't exist", drift.RoleName)
+	}
+
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		switch {
+		case fromDB && drift.Kind == dto.RoleDriftMissingInCasbin:
+			return casbin.AssignRoleToUser(drift.UserID, drift.RoleName)
+		case fromDB:
+			return casbin.RemoveRoleFromUser(drift.UserID, drift.RoleName)
+		case drift.Kind == dto.RoleDriftMissingInCasbin:
+			return s.userRepo.WithTx(tx).RemoveRoleFromUser(drift.UserID, drift.RoleID)
+		default:
+			return s.userRepo.WithTx(tx).AssignRoleToUser(drift.UserID, drift.RoleID, nil)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/application/dto"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	userModel "github.com/FeisalDy/nogo/internal/user/model"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
+)
+
+// UserStatusService handles account status changes that span multiple domains
+// This is part of the Application Layer
+type UserStatusService struct {
+	userService  *userService.UserService
+	tokenService *authService.TokenService
+}
+
+// NewUserStatusService creates a new instance of UserStatusService
+func NewUserStatusService(userSvc *userService.UserService, tokenService *authService.TokenService) *UserStatusService {
+	return &UserStatusService{
+		userService:  userSvc,
+		tokenService: tokenService,
+	}
+}
+
+// ChangeStatus changes a user's account status on behalf of an admin
+// This is a cross-domain operation that:
+// 1. Updates the status and records the change with its reason and actor (User domain)
+// 2. Signs the user out everywhere unless the account is active again (Auth domain)
+func (s *UserStatusService) ChangeStatus(userID uint, req *dto.UpdateUserStatusDTO, changedBy uint) (*dto.UserStatusDTO, error) {
+	user, err := s.userService.ChangeStatus(userID, req.Status, req.Reason, req.Until, changedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != userModel.StatusActive {
+		if err := s.tokenService.RevokeAllForUser(user.ID); err != nil {
+			log.Printf("Failed to revoke sessions of user %d after status change: %v", user.ID, err)
+		}
+	}
+
+	return s.buildStatus(user)
+}
+
+// GetStatus gets a user's account status and its history
+func (s *UserStatusService) GetStatus(userID uint) (*dto.UserStatusDTO, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	return s.buildStatus(user)
+}
+
+func (s *UserStatusService) buildStatus(user *userModel.User) (*dto.UserStatusDTO, error) {
+	history, err := s.userService.GetStatusHistory(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := user.EffectiveStatus(time.Now())
+	result := &dto.UserStatusDTO{
+		UserID:    user.ID,
+		Status:    status,
+		ChangedAt: user.StatusChangedAt,
+		ChangedBy: user.StatusChangedBy,
+		History:   history,
+	}
+	if status != userModel.StatusActive {
+		result.Reason = user.StatusReason
+		result.Until = user.StatusUntil
+	}
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Email verification token purposes
+const (
+	EmailTokenPurposeVerify = "verify" // confirms the user's current address
+	EmailTokenPurposeChange = "change" // confirms a new address the user wants to switch to
+)
+
+// EmailVerificationToken is a single-use token that confirms an email address
+// Only the SHA-256 hash of the token is stored
+type EmailVerificationToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Email     string     `json:"email" gorm:"not null"`
+	Purpose   string     `json:"purpose" gorm:"not null;size:20;default:'verify'"`
+	TokenHash string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+// IsActive reports whether the token can still be used
+func (t *EmailVerificationToken) IsActive(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MFASecret is the TOTP secret of a user
+// Enrollment is pending until EnabledAt is set
+type MFASecret struct {
+	gorm.Model
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"not null;size:64"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+}
+
+func (MFASecret) TableName() string {
+	return "mfa_secrets"
+}
+
+// IsEnabled reports whether the enrollment was confirmed
+func (s *MFASecret) IsEnabled() bool {
+	return s.EnabledAt != nil
+}
+
+// MFARecoveryCode is a single-use code that replaces a TOTP code
+// Only the SHA-256 hash of the code is stored
+type MFARecoveryCode struct {
+	gorm.Model
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash string     `json:"-" gorm:"not null;size:64;index"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
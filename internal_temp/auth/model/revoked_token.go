@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevokedToken records a revoked access token (JTI set), a revoked session
+// (SessionID set) or a per-user cutoff that revokes every token issued before IssuedBefore
+type RevokedToken struct {
+	gorm.Model
+	JTI          *string    `json:"jti" gorm:"column:jti;size:64;uniqueIndex"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	SessionID    *uint      `json:"session_id" gorm:"index"`
+	IssuedBefore *time.Time `json:"issued_before"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null;index"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
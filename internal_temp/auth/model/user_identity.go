@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity is an external account (e.g. Google, Discord) linked to a user
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint    `json:"user_id" gorm:"not null;index"`
+	Provider string  `json:"provider" gorm:"not null;size:64;uniqueIndex:idx_identity_provider_subject"`
+	Subject  string  `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	Email    *string `json:"email"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCLoginState is a social login in progress, between the redirect to the provider and the callback
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string    `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Provider     string    `json:"provider" gorm:"not null;size:64"`
+	Nonce        string    `json:"-" gorm:"not null;size:64"`
+	CodeVerifier string    `json:"-" gorm:"not null;size:128"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession is a login on one device, tied to the refresh token family of that login
+// LastSeenAt is updated on login and on every refresh token rotation
+// AuthenticatedAt is when the user logged in, nil for sessions recorded on the first rotation
+// of a token family issued before sessions were recorded
+type UserSession struct {
+	gorm.Model
+	UserID          uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID        string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	UserAgent       string     `json:"user_agent" gorm:"size:512"`
+	IP              string     `json:"ip" gorm:"column:ip;size:45"`
+	LastSeenAt      time.Time  `json:"last_seen_at" gorm:"not null"`
+	AuthenticatedAt *time.Time `json:"authenticated_at"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive reports whether the session can still be used
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AuthenticatedSince reports whether the user logged in for this session at or after since
+func (s *UserSession) AuthenticatedSince(since time.Time) bool {
+	return s.AuthenticatedAt != nil && !s.AuthenticatedAt.Before(since)
+}
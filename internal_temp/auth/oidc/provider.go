@@ -0,0 +1,355 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity is the external account returned by a provider after a successful login
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is an OAuth2 / OpenID Connect provider using the authorization code flow with PKCE
+// Endpoints are discovered from the issuer's /.well-known/openid-configuration unless they are
+// configured explicitly (needed for plain OAuth2 providers such as GitHub)
+type Provider struct {
+	cfg         config.OIDCProviderConfig
+	redirectURL string
+	httpClient  *http.Client
+
+	mu        sync.Mutex
+	endpoints *endpoints
+	keys      map[string]any
+}
+
+type endpoints struct {
+	Issuer      string `json:"issuer"`
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+	JWKSURL     string `json:"jwks_uri"`
+}
+
+func NewProvider(cfg config.OIDCProviderConfig, redirectURL string) *Provider {
+	return &Provider{
+		cfg:         cfg,
+		redirectURL: redirectURL,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the configured provider name, e.g. "google"
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL builds the URL the user is sent to in order to log in at the provider
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	ep, err := p.getEndpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", CodeChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+	if nonce != "" {
+		params.Set("nonce", nonce)
+	}
+
+	separator := "?"
+	if strings.Contains(ep.AuthURL, "?") {
+		separator = "&"
+	}
+	return ep.AuthURL + separator + params.Encode(), nil
+}
+
+// Exchange trades an authorization code for the user's identity
+// The ID token is verified when the provider returns one; otherwise the userinfo endpoint is used
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	ep, err := p.getEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+		Error       string `json:"error"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s", tokens.Error)
+	}
+
+	var claims map[string]any
+	if tokens.IDToken != "" {
+		claims, err = p.verifyIDToken(ctx, tokens.IDToken, nonce)
+	} else {
+		claims, err = p.userInfo(ctx, tokens.AccessToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.identityFromClaims(claims)
+}
+
+func (p *Provider) identityFromClaims(claims map[string]any) (*Identity, error) {
+	subjectClaim := p.cfg.SubjectClaim
+	if subjectClaim == "" {
+		subjectClaim = "sub"
+	}
+
+	subject := claimString(claims, subjectClaim)
+	if subject == "" {
+		return nil, fmt.Errorf("provider response has no %q claim", subjectClaim)
+	}
+
+	name := claimString(claims, "name")
+	if name == "" {
+		name = claimString(claims, "preferred_username")
+	}
+	if name == "" {
+		name = claimString(claims, "login")
+	}
+
+	verified, _ := claims["email_verified"].(bool)
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       subject,
+		Email:         strings.ToLower(claimString(claims, "email")),
+		EmailVerified: verified,
+		Name:          name,
+	}, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *Provider) verifyIDToken(ctx context.Context, idToken, nonce string) (map[string]any, error) {
+	ep, err := p.getEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	}
+	if ep.Issuer != "" {
+		options = append(options, jwt.WithIssuer(ep.Issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if nonce != "" && claimString(claims, "nonce") != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) userInfo(ctx context.Context, accessToken string) (map[string]any, error) {
+	ep, err := p.getEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ep.UserInfoURL == "" {
+		return nil, errors.New("provider returned no id token and has no userinfo endpoint")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	claims := map[string]any{}
+	if err := p.doJSON(req, &claims); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	return claims, nil
+}
+
+// getEndpoints returns the configured endpoints, discovering missing ones once
+func (p *Provider) getEndpoints(ctx context.Context) (*endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+
+	ep := &endpoints{
+		Issuer:      p.cfg.IssuerURL,
+		AuthURL:     p.cfg.AuthURL,
+		TokenURL:    p.cfg.TokenURL,
+		UserInfoURL: p.cfg.UserInfoURL,
+		JWKSURL:     p.cfg.JWKSURL,
+	}
+
+	if ep.AuthURL == "" || ep.TokenURL == "" {
+		if p.cfg.IssuerURL == "" {
+			return nil, fmt.Errorf("provider %s needs an issuer or explicit endpoints", p.cfg.Name)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var discovered endpoints
+		if err := p.doJSON(req, &discovered); err != nil {
+			return nil, fmt.Errorf("discovery failed for provider %s: %w", p.cfg.Name, err)
+		}
+		if strings.TrimSuffix(discovered.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+			return nil, fmt.Errorf("discovery for provider %s returned issuer %q", p.cfg.Name, discovered.Issuer)
+		}
+
+		ep.Issuer = discovered.Issuer
+		ep.AuthURL = firstNonEmpty(ep.AuthURL, discovered.AuthURL)
+		ep.TokenURL = firstNonEmpty(ep.TokenURL, discovered.TokenURL)
+		ep.UserInfoURL = firstNonEmpty(ep.UserInfoURL, discovered.UserInfoURL)
+		ep.JWKSURL = firstNonEmpty(ep.JWKSURL, discovered.JWKSURL)
+	}
+
+	p.endpoints = ep
+	return ep, nil
+}
+
+// getKey returns the provider's signing key with the given kid
+// The key set is fetched again when an unknown kid shows up, which handles key rotation
+func (p *Provider) getKey(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	jwksURL := ""
+	if p.endpoints != nil {
+		jwksURL = p.endpoints.JWKSURL
+	}
+	p.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+	if jwksURL == "" {
+		return nil, errors.New("provider has no jwks_uri")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwkSet
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) doJSON(req *http.Request, out any) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// CodeChallenge derives the S256 PKCE code challenge from a code verifier (RFC 7636)
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// claimString reads a claim as a string; numeric IDs (GitHub) are formatted without decimals
+func claimString(claims map[string]any, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository handles personal API key persistence
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) WithTx(tx *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: tx}
+}
+
+func (r *APIKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByHash gets an API key by hash
+func (r *APIKeyRepository) GetByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByUserID gets every API key of a user, newest first
+func (r *APIKeyRepository) GetByUserID(userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke revokes an API key of a user
+// Returns false if the user has no unrevoked key with that ID
+func (r *APIKeyRepository) Revoke(userID, keyID uint, revokedAt time.Time) (bool, error) {
+	result := r.db.
+		Model(&model.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", revokedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllForUser revokes every API key of a user
+func (r *APIKeyRepository) RevokeAllForUser(userID uint, revokedAt time.Time) error {
+	return r.db.
+		Model(&model.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
+
+// TouchLastUsed records when a key was last used
+func (r *APIKeyRepository) TouchLastUsed(keyID uint, usedAt time.Time) error {
+	return r.db.
+		Model(&model.APIKey{}).
+		Where("id = ?", keyID).
+		Update("last_used_at", usedAt).Error
+}
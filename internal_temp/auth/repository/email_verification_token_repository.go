@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailVerificationTokenRepository handles email verification token persistence
+type EmailVerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationTokenRepository(db *gorm.DB) *EmailVerificationTokenRepository {
+	return &EmailVerificationTokenRepository{db: db}
+}
+
+func (r *EmailVerificationTokenRepository) WithTx(tx *gorm.DB) *EmailVerificationTokenRepository {
+	return &EmailVerificationTokenRepository{db: tx}
+}
+
+func (r *EmailVerificationTokenRepository) Create(token *model.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHashForUpdate gets a verification token by hash and locks the row
+// Must be called inside a transaction so the token can only be used once
+func (r *EmailVerificationTokenRepository) GetByHashForUpdate(tokenHash string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a verification token as used
+func (r *EmailVerificationTokenRepository) MarkUsed(id uint, usedAt time.Time) error {
+	return r.db.
+		Model(&model.EmailVerificationToken{}).
+		Where("id = ?", id).
+		Update("used_at", usedAt).Error
+}
+
+// InvalidateForUser marks every pending token of a user with the given purpose as used
+func (r *EmailVerificationTokenRepository) InvalidateForUser(userID uint, purpose string, usedAt time.Time) error {
+	return r.db.
+		Model(&model.EmailVerificationToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", usedAt).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginThrottleRepository handles failed login attempt counters
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) WithTx(tx *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: tx}
+}
+
+// GetByKeys gets the counters for the given keys; keys without failures are absent
+func (r *LoginThrottleRepository) GetByKeys(keys []string) ([]model.LoginThrottle, error) {
+	var throttles []model.LoginThrottle
+	err := r.db.Where("key IN ?", keys).Find(&throttles).Error
+	return throttles, err
+}
+
+// GetOrCreateForUpdate gets the counter for a key, creating it if needed, and locks the row
+// Must be called inside a transaction so concurrent failures are all counted
+func (r *LoginThrottleRepository) GetOrCreateForUpdate(key string) (*model.LoginThrottle, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoginThrottle{Key: key}).Error; err != nil {
+		return nil, err
+	}
+
+	var throttle model.LoginThrottle
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("key = ?", key).
+		First(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *LoginThrottleRepository) Save(throttle *model.LoginThrottle) error {
+	return r.db.Save(throttle).Error
+}
+
+// DeleteByKeys removes the counters for the given keys, which unlocks them
+func (r *LoginThrottleRepository) DeleteByKeys(keys []string) error {
+	return r.db.Unscoped().Where("key IN ?", keys).Delete(&model.LoginThrottle{}).Error
+}
+
+// GetLocked gets every counter that is locked at the given time
+func (r *LoginThrottleRepository) GetLocked(now time.Time) ([]model.LoginThrottle, error) {
+	var throttles []model.LoginThrottle
+	err := r.db.
+		Where("locked_until > ?", now).
+		Order("locked_until DESC").
+		Find(&throttles).Error
+	return throttles, err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+)
+
+// MFARepository handles TOTP secrets and recovery codes
+type MFARepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) WithTx(tx *gorm.DB) *MFARepository {
+	return &MFARepository{db: tx}
+}
+
+// GetSecretByUserID gets the TOTP secret of a user
+func (r *MFARepository) GetSecretByUserID(userID uint) (*model.MFASecret, error) {
+	var secret model.MFASecret
+	if err := r.db.Where("user_id = ?", userID).First(&secret).Error; err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// ReplacePendingSecret stores a new, not yet enabled secret for the user
+// Any earlier secret and recovery codes are removed
+func (r *MFARepository) ReplacePendingSecret(userID uint, secret string) error {
+	if err := r.DeleteForUser(userID); err != nil {
+		return err
+	}
+	return r.db.Create(&model.MFASecret{UserID: userID, Secret: secret}).Error
+}
+
+// Enable confirms the enrollment and records the time step of the confirming code
+func (r *MFARepository) Enable(id uint, enabledAt time.Time, step int64) error {
+	return r.db.
+		Model(&model.MFASecret{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"enabled_at": enabledAt, "last_used_step": step}).Error
+}
+
+// AdvanceLastUsedStep records an accepted time step
+// Returns false if the step (or a later one) was already used, i.e. the code is replayed
+func (r *MFARepository) AdvanceLastUsedStep(id uint, step int64) (bool, error) {
+	result := r.db.
+		Model(&model.MFASecret{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteForUser removes the secret and recovery codes of a user
+func (r *MFARepository) DeleteForUser(userID uint) error {
+	if err := r.db.Unscoped().Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return r.db.Unscoped().Where("user_id = ?", userID).Delete(&model.MFASecret{}).Error
+}
+
+// ReplaceRecoveryCodes swaps every recovery code of a user for the given hashes
+func (r *MFARepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	if err := r.db.Unscoped().Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]model.MFARecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.MFARecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return r.db.Create(&codes).Error
+}
+
+// UseRecoveryCode marks an unused recovery code as used
+// Returns false if the user has no unused code with that hash
+func (r *MFARepository) UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.
+		Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountUnusedRecoveryCodes counts the recovery codes a user has left
+func (r *MFARepository) CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.
+		Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
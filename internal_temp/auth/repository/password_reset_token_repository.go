@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PasswordResetTokenRepository handles password reset token persistence
+type PasswordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) *PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{db: db}
+}
+
+func (r *PasswordResetTokenRepository) WithTx(tx *gorm.DB) *PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{db: tx}
+}
+
+func (r *PasswordResetTokenRepository) Create(token *model.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHashForUpdate gets a reset token by hash and locks the row
+// Must be called inside a transaction so the token can only be used once
+func (r *PasswordResetTokenRepository) GetByHashForUpdate(tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a reset token as used
+func (r *PasswordResetTokenRepository) MarkUsed(id uint, usedAt time.Time) error {
+	return r.db.
+		Model(&model.PasswordResetToken{}).
+		Where("id = ?", id).
+		Update("used_at", usedAt).Error
+}
+
+// InvalidateForUser marks every pending reset token of a user as used
+func (r *PasswordResetTokenRepository) InvalidateForUser(userID uint, usedAt time.Time) error {
+	return r.db.
+		Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
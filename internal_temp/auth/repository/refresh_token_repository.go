@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefreshTokenRepository handles refresh token persistence
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) WithTx(tx *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: tx}
+}
+
+func (r *RefreshTokenRepository) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash gets a refresh token by hash
+func (r *RefreshTokenRepository) GetByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByHashForUpdate gets a refresh token by hash and locks the row
+// Must be called inside a transaction so concurrent rotations are serialized
+func (r *RefreshTokenRepository) GetByHashForUpdate(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a refresh token as exchanged
+func (r *RefreshTokenRepository) MarkUsed(id uint, usedAt time.Time) error {
+	return r.db.
+		Model(&model.RefreshToken{}).
+		Where("id = ?", id).
+		Update("used_at", usedAt).Error
+}
+
+// RevokeFamily revokes every token that belongs to a token family
+func (r *RefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+	return r.db.
+		Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RevokeAllForUser revokes every refresh token of a user
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uint, revokedAt time.Time) error {
+	return r.db.
+		Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository handles revoked token persistence
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+func (r *RevokedTokenRepository) WithTx(tx *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: tx}
+}
+
+// Create stores a revocation, ignoring a duplicate JTI
+func (r *RevokedTokenRepository) Create(revoked *model.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(revoked).Error
+}
+
+// CreateUnique stores a revocation and reports whether it is new, false when its JTI was already revoked
+func (r *RevokedTokenRepository) CreateUnique(revoked *model.RevokedToken) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(revoked)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetActiveSince gets all unexpired revocations created at or after the given time
+func (r *RevokedTokenRepository) GetActiveSince(since, now time.Time) ([]model.RevokedToken, error) {
+	var revoked []model.RevokedToken
+	err := r.db.
+		Where("created_at >= ? AND expires_at > ?", since, now).
+		Find(&revoked).Error
+	return revoked, err
+}
+
+// DeleteExpired permanently removes revocations that no longer matter
+func (r *RevokedTokenRepository) DeleteExpired(now time.Time) error {
+	return r.db.Unscoped().
+		Where("expires_at <= ?", now).
+		Delete(&model.RevokedToken{}).Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserIdentityRepository handles linked external accounts and social login state
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+func (r *UserIdentityRepository) WithTx(tx *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: tx}
+}
+
+func (r *UserIdentityRepository) Create(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// GetByProviderSubject gets the identity of an external account
+func (r *UserIdentityRepository) GetByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUserID gets every external account linked to a user
+func (r *UserIdentityRepository) GetByUserID(userID uint) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepository) CreateState(state *model.OIDCLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// ConsumeState deletes a login state and returns it, so it can only be used once
+// Must be called inside a transaction
+func (r *UserIdentityRepository) ConsumeState(stateHash string) (*model.OIDCLoginState, error) {
+	var state model.OIDCLoginState
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("state_hash = ?", stateHash).
+		First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Unscoped().Delete(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteExpiredStates removes logins that were never completed
+func (r *UserIdentityRepository) DeleteExpiredStates(now time.Time) error {
+	return r.db.Unscoped().
+		Where("expires_at <= ?", now).
+		Delete(&model.OIDCLoginState{}).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"gorm.io/gorm"
+)
+
+// UserSessionRepository handles login session persistence
+type UserSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewUserSessionRepository(db *gorm.DB) *UserSessionRepository {
+	return &UserSessionRepository{db: db}
+}
+
+func (r *UserSessionRepository) WithTx(tx *gorm.DB) *UserSessionRepository {
+	return &UserSessionRepository{db: tx}
+}
+
+func (r *UserSessionRepository) Create(session *model.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetByFamilyID gets the session of a refresh token family
+func (r *UserSessionRepository) GetByFamilyID(familyID string) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("family_id = ?", familyID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByIDAndUserID gets a session that belongs to the user
+func (r *UserSessionRepository) GetByIDAndUserID(id, userID uint) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveByUserID gets the unrevoked, unexpired sessions of a user, most recently seen first
+func (r *UserSessionRepository) GetActiveByUserID(userID uint, now time.Time) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records activity on a session and extends it to the new refresh token expiry
+func (r *UserSessionRepository) Touch(id uint, userAgent, ip string, seenAt, expiresAt time.Time) error {
+	return r.db.
+		Model(&model.UserSession{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"user_agent":   userAgent,
+			"ip":           ip,
+			"last_seen_at": seenAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// Revoke revokes a session
+func (r *UserSessionRepository) Revoke(id uint, revokedAt time.Time) error {
+	return r.db.
+		Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RevokeAllForUser revokes every session of a user
+func (r *UserSessionRepository) RevokeAllForUser(userID uint, revokedAt time.Time) error {
+	return r.db.
+		Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	stdErrors "errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix starts every API key so leaked keys are easy to recognize
+	APIKeyPrefix = "nogo_"
+
+	// lastUsedResolution limits how often last_used_at is written for a busy key
+	lastUsedResolution = time.Minute
+)
+
+// APIKeyService manages scoped personal API keys
+// A key acts as its user, but only for the "resource:action" scopes it was created with
+type APIKeyService struct {
+	apiKeyRepo    *repository.APIKeyRepository
+	casbinService *casbinService.CasbinService
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, casbin *casbinService.CasbinService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:    apiKeyRepo,
+		casbinService: casbin,
+	}
+}
+
+// CreateKey creates an API key limited to the given scopes
+// Every scope must be a permission the user currently has. Returns the plain key, which is only shown once
+func (s *APIKeyService) CreateKey(userID uint, name string, scopes []string, expiresAt *time.Time) (*model.APIKey, string, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.NewAppError(errors.ErrCodeValidationFailed, "API key expiry must be in the future")
+	}
+
+	normalized := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		resource, action, ok := ParseScope(scope)
+		if !ok {
+			return nil, "", errors.NewAppError(errors.ErrCodeAuthAPIKeyScope, "Invalid scope "+scope+", expected resource:action")
+		}
+
+		allowed, err := s.casbinService.Enforce(userID, resource, action)
+		if err != nil {
+			return nil, "", err
+		}
+		if !allowed {
+			return nil, "", errors.NewAppError(errors.ErrCodeAuthAPIKeyScope, "You don't have the permission "+scope)
+		}
+
+		scope = resource + ":" + action
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	secret, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plainKey := APIKeyPrefix + secret
+
+	key := &model.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plainKey[:len(APIKeyPrefix)+8],
+		KeyHash:   utils.HashToken(plainKey),
+		Scopes:    normalized,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plainKey, nil
+}
+
+// ListKeys lists the API keys of a user
+func (s *APIKeyService) ListKeys(userID uint) ([]model.APIKey, error) {
+	return s.apiKeyRepo.GetByUserID(userID)
+}
+
+// RevokeKey revokes an API key of the user
+func (s *APIKeyService) RevokeKey(userID, keyID uint) error {
+	revoked, err := s.apiKeyRepo.Revoke(userID, keyID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a plain API key to its active key record
+func (s *APIKeyService) Authenticate(plainKey string) (*model.APIKey, error) {
+	if !strings.HasPrefix(plainKey, APIKeyPrefix) {
+		return nil, errors.ErrAuthAPIKeyInvalid
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(utils.HashToken(plainKey))
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAuthAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, errors.ErrAuthAPIKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("Failed to record API key usage: %v", err)
+		}
+	}
+
+	return key, nil
+}
+
+// ParseScope splits a "resource:action" scope
+func ParseScope(scope string) (string, string, bool) {
+	resource, action, ok := strings.Cut(strings.TrimSpace(scope), ":")
+	if !ok || resource == "" || action == "" {
+		return "", "", false
+	}
+	return resource, action, true
+}
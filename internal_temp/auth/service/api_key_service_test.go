@@ -0,0 +1,138 @@
+package service
+
+import (
+	stdErrors "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	commonModel "github.com/FeisalDy/nogo/internal/common/model"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+)
+
+// newTestAPIKeyService returns an API key service for user 3, who is a "reader" allowed to read and list novels
+func newTestAPIKeyService(t *testing.T) *APIKeyService {
+	t.Helper()
+
+	db := databasetest.Open(t, &model.APIKey{}, &commonModel.AuthzAudit{})
+	if _, err := casbinService.InitCasbin(db, "../../../config/casbin/model.conf"); err != nil {
+		t.Fatalf("init casbin: %v", err)
+	}
+
+	enforcer := casbinService.GetEnforcer()
+	if _, err := enforcer.AddPolicies([][]string{{"reader", "novels", "read"}, {"reader", "novels", "list"}}); err != nil {
+		t.Fatalf("add policies: %v", err)
+	}
+	if _, err := enforcer.AddGroupingPolicy(casbinService.FormatUserSubject(3), "reader"); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	return NewAPIKeyService(repository.NewAPIKeyRepository(db), casbinService.NewCasbinService(db))
+}
+
+func TestCreateKeyScopes(t *testing.T) {
+	s := newTestAPIKeyService(t)
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		wantScopes []string
+		wantCode   string
+	}{
+		{name: "subset of the user's permissions", scopes: []string{"novels:read"}, wantScopes: []string{"novels:read"}},
+		{name: "duplicates and spaces are dropped", scopes: []string{" novels:read", "novels:list", "novels:read "}, wantScopes: []string{"novels:read", "novels:list"}},
+		{name: "no scopes", scopes: nil, wantScopes: []string{}},
+		{name: "permission the user lacks", scopes: []string{"novels:read", "novels:delete"}, wantCode: errors.ErrCodeAuthAPIKeyScope},
+		{name: "malformed scope", scopes: []string{"novels"}, wantCode: errors.ErrCodeAuthAPIKeyScope},
+		{name: "empty action", scopes: []string{"novels:"}, wantCode: errors.ErrCodeAuthAPIKeyScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, plainKey, err := s.CreateKey(3, "test", tt.scopes, nil)
+			if tt.wantCode != "" {
+				var appErr *errors.AppError
+				if !stdErrors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Fatalf("CreateKey() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+			if !slices.Equal(key.Scopes, tt.wantScopes) {
+				t.Errorf("CreateKey() scopes = %v, want %v", key.Scopes, tt.wantScopes)
+			}
+
+			authenticated, err := s.Authenticate(plainKey)
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if authenticated.ID != key.ID || !slices.Equal(authenticated.Scopes, tt.wantScopes) {
+				t.Errorf("Authenticate() = key %d scopes %v, want key %d scopes %v", authenticated.ID, authenticated.Scopes, key.ID, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	s := newTestAPIKeyService(t)
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, key *model.APIKey, plainKey string) string
+		wantErr error
+	}{
+		{
+			name:    "active key",
+			prepare: func(t *testing.T, key *model.APIKey, plainKey string) string { return plainKey },
+		},
+		{
+			name: "revoked key",
+			prepare: func(t *testing.T, key *model.APIKey, plainKey string) string {
+				if err := s.RevokeKey(3, key.ID); err != nil {
+					t.Fatalf("RevokeKey() error = %v", err)
+				}
+				return plainKey
+			},
+			wantErr: errors.ErrAuthAPIKeyInvalid,
+		},
+		{
+			name: "key of another user can't be revoked",
+			prepare: func(t *testing.T, key *model.APIKey, plainKey string) string {
+				if err := s.RevokeKey(4, key.ID); !stdErrors.Is(err, errors.ErrNotFound) {
+					t.Fatalf("RevokeKey() error = %v, want %v", err, errors.ErrNotFound)
+				}
+				return plainKey
+			},
+		},
+		{
+			name:    "unknown key",
+			prepare: func(t *testing.T, key *model.APIKey, plainKey string) string { return APIKeyPrefix + "unknown" },
+			wantErr: errors.ErrAuthAPIKeyInvalid,
+		},
+		{
+			name:    "key without the prefix",
+			prepare: func(t *testing.T, key *model.APIKey, plainKey string) string { return plainKey[len(APIKeyPrefix):] },
+			wantErr: errors.ErrAuthAPIKeyInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt := time.Now().Add(time.Hour)
+			key, plainKey, err := s.CreateKey(3, "test", []string{"novels:read"}, &expiresAt)
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+
+			_, err = s.Authenticate(tt.prepare(t, key, plainKey))
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
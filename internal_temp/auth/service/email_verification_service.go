@@ -0,0 +1,137 @@
+package service
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/mailer"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"gorm.io/gorm"
+)
+
+// EmailVerificationService issues email verification tokens and mails them
+type EmailVerificationService struct {
+	tokenRepo *repository.EmailVerificationTokenRepository
+	mailer    mailer.Mailer
+	ttl       time.Duration
+	linkURL   string
+}
+
+func NewEmailVerificationService(
+	tokenRepo *repository.EmailVerificationTokenRepository,
+	m mailer.Mailer,
+	cfg config.AuthConfig,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		tokenRepo: tokenRepo,
+		mailer:    m,
+		ttl:       cfg.EmailVerificationTTL,
+		linkURL:   cfg.EmailVerificationURL,
+	}
+}
+
+// SendVerification issues a new verification token for the email address and mails the link
+// Earlier pending verification tokens of the user are invalidated
+func (s *EmailVerificationService) SendVerification(userID uint, email string) error {
+	link, err := s.issue(userID, email, model.EmailTokenPurposeVerify)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Please confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not create an account, you can ignore this email.",
+			link, s.ttl,
+		),
+	})
+}
+
+// SendEmailChange mails a link that confirms the new email address a user wants to switch to
+// The address only changes once the link is opened. Earlier pending email changes of the user are invalidated
+func (s *EmailVerificationService) SendEmailChange(userID uint, newEmail string) error {
+	link, err := s.issue(userID, newEmail, model.EmailTokenPurposeChange)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Please confirm that you want to use this email address for your account by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.",
+			link, s.ttl,
+		),
+	})
+}
+
+// SendEmailChangedNotice tells the previous address that the account's email was changed
+func (s *EmailVerificationService) SendEmailChangedNotice(oldEmail, newEmail string) error {
+	return s.mailer.Send(mailer.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf(
+			"The email address of your account was changed to %s.\n\nIf you did not make this change, please reset your password and contact support.",
+			newEmail,
+		),
+	})
+}
+
+// issue stores a new token for the address and returns the link that consumes it
+func (s *EmailVerificationService) issue(userID uint, email, purpose string) (string, error) {
+	plainToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.tokenRepo.InvalidateForUser(userID, purpose, now); err != nil {
+		return "", err
+	}
+
+	token := &model.EmailVerificationToken{
+		UserID:    userID,
+		Email:     email,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return "", err
+	}
+
+	return s.linkURL + "?token=" + url.QueryEscape(plainToken), nil
+}
+
+// ConsumeToken validates a verification token and marks it as used
+// Must be called inside a transaction; the caller marks the email as verified, or
+// switches to the new address for an email change, in the same tx
+func (s *EmailVerificationService) ConsumeToken(tx *gorm.DB, plainToken string) (*model.EmailVerificationToken, error) {
+	repo := s.tokenRepo.WithTx(tx)
+
+	token, err := repo.GetByHashForUpdate(utils.HashToken(plainToken))
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAuthVerifyInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, errors.ErrAuthVerifyInvalid
+	}
+
+	if err := repo.MarkUsed(token.ID, now); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
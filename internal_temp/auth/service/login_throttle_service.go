@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/database"
+	"gorm.io/gorm"
+)
+
+// LoginThrottleService protects login against password guessing
+// Failed attempts are counted per email, per IP and per user for MFA codes.
+// Once a key reaches its threshold it is locked, and every further failure
+// doubles the lockout up to the configured maximum.
+// Password reset requests are counted the same way, so they can't flood an inbox
+type LoginThrottleService struct {
+	throttleRepo     *repository.LoginThrottleRepository
+	maxAttempts      int
+	ipMaxAttempts    int
+	resetMaxRequests int
+	window           time.Duration
+	baseLockout      time.Duration
+	maxLockout       time.Duration
+}
+
+func NewLoginThrottleService(throttleRepo *repository.LoginThrottleRepository, cfg config.AuthConfig) *LoginThrottleService {
+	return &LoginThrottleService{
+		throttleRepo:     throttleRepo,
+		maxAttempts:      cfg.LoginMaxAttempts,
+		ipMaxAttempts:    cfg.LoginIPMaxAttempts,
+		resetMaxRequests: cfg.PasswordResetMaxRequests,
+		window:           cfg.LoginAttemptWindow,
+		baseLockout:      cfg.LoginLockoutBase,
+		maxLockout:       cfg.LoginLockoutMax,
+	}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func mfaKey(userID uint) string {
+	return fmt.Sprintf("mfa:%d", userID)
+}
+
+// resetKey counts password reset requests apart from login failures, so they don't lock the login
+func resetKey(key string) string {
+	return "reset:" + key
+}
+
+// CheckLogin returns an error if the email or the IP address is currently locked
+func (s *LoginThrottleService) CheckLogin(email, ip string) error {
+	return s.check(map[string]*errors.AppError{
+		emailKey(email): errors.ErrAuthAccountLocked,
+		ipKey(ip):       errors.ErrAuthTooManyAttempts,
+	})
+}
+
+// RecordLoginFailure counts a failed password attempt for the email and the IP address
+func (s *LoginThrottleService) RecordLoginFailure(email, ip string) error {
+	if err := s.recordFailure(emailKey(email), s.maxAttempts); err != nil {
+		return err
+	}
+	return s.recordFailure(ipKey(ip), s.ipMaxAttempts)
+}
+
+// RecordLoginSuccess clears the failures of the email
+// The IP counter is kept, so one valid account doesn't reset guessing on others
+func (s *LoginThrottleService) RecordLoginSuccess(email string) error {
+	return s.throttleRepo.DeleteByKeys([]string{emailKey(email)})
+}
+
+// CheckMFA returns an error if second-factor attempts of the user are currently locked
+func (s *LoginThrottleService) CheckMFA(userID uint) error {
+	return s.check(map[string]*errors.AppError{mfaKey(userID): errors.ErrAuthAccountLocked})
+}
+
+// RecordMFAFailure counts a failed second-factor attempt
+func (s *LoginThrottleService) RecordMFAFailure(userID uint) error {
+	return s.recordFailure(mfaKey(userID), s.maxAttempts)
+}
+
+// RecordMFASuccess clears the second-factor failures of the user
+func (s *LoginThrottleService) RecordMFASuccess(userID uint) error {
+	return s.throttleRepo.DeleteByKeys([]string{mfaKey(userID)})
+}
+
+// CheckPasswordReset returns an error if password resets for the email or from the IP address are currently locked
+func (s *LoginThrottleService) CheckPasswordReset(email, ip string) error {
+	return s.check(map[string]*errors.AppError{
+		resetKey(emailKey(email)): errors.ErrAuthTooManyResets,
+		resetKey(ipKey(ip)):       errors.ErrAuthTooManyResets,
+	})
+}
+
+// RecordPasswordReset counts a password reset request for the email and the IP address
+func (s *LoginThrottleService) RecordPasswordReset(email, ip string) error {
+	if err := s.recordFailure(resetKey(emailKey(email)), s.resetMaxRequests); err != nil {
+		return err
+	}
+	return s.recordFailure(resetKey(ipKey(ip)), s.ipMaxAttempts)
+}
+
+// Unlock removes the lockout of an account and, optionally, of an IP address
+func (s *LoginThrottleService) Unlock(userID uint, email, ip string) error {
+	keys := []string{emailKey(email), mfaKey(userID), resetKey(emailKey(email))}
+	if ip != "" {
+		keys = append(keys, ipKey(ip), resetKey(ipKey(ip)))
+	}
+	return s.throttleRepo.DeleteByKeys(keys)
+}
+
+// check returns the error of the first locked key, with the seconds to wait in its details
+func (s *LoginThrottleService) check(keyErrors map[string]*errors.AppError) error {
+	keys := make([]string, 0, len(keyErrors))
+	for key := range keyErrors {
+		keys = append(keys, key)
+	}
+
+	throttles, err := s.throttleRepo.GetByKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, throttle := range throttles {
+		if !throttle.IsLocked(now) {
+			continue
+		}
+
+		base := keyErrors[throttle.Key]
+		retryAfter := int64(math.Ceil(throttle.LockedUntil.Sub(now).Seconds()))
+		return errors.NewAppError(base.Code, base.Message).WithDetails(map[string]interface{}{
+			"retry_after": retryAfter,
+		})
+	}
+	return nil
+}
+
+// recordFailure increments the failures of a key and locks it once the limit is reached
+// Failures older than the attempt window are forgotten
+func (s *LoginThrottleService) recordFailure(key string, limit int) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.throttleRepo.WithTx(tx)
+
+		throttle, err := repo.GetOrCreateForUpdate(key)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if throttle.LastFailureAt != nil && now.Sub(*throttle.LastFailureAt) > s.window && !throttle.IsLocked(now) {
+			throttle.Failures = 0
+		}
+
+		throttle.Failures++
+		throttle.LastFailureAt = &now
+
+		if limit > 0 && throttle.Failures >= limit {
+			lockedUntil := now.Add(s.lockoutFor(throttle.Failures - limit))
+			throttle.LockedUntil = &lockedUntil
+		}
+
+		return repo.Save(throttle)
+	})
+}
+
+// lockoutFor returns the lockout after the given number of failures past the limit
+func (s *LoginThrottleService) lockoutFor(excess int) time.Duration {
+	lockout := s.baseLockout
+	for i := 0; i < excess && lockout < s.maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > s.maxLockout {
+		lockout = s.maxLockout
+	}
+	return lockout
+}
+
+// GetLockedThrottles lists the keys that are locked right now
+func (s *LoginThrottleService) GetLockedThrottles() ([]model.LoginThrottle, error) {
+	return s.throttleRepo.GetLocked(time.Now())
+}
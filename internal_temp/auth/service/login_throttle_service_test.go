@@ -0,0 +1,227 @@
+package service
+
+import (
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/database"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+)
+
+func newTestThrottleService(t *testing.T) *LoginThrottleService {
+	t.Helper()
+
+	db := databasetest.Open(t, &model.LoginThrottle{})
+	database.DB = db
+	return NewLoginThrottleService(repository.NewLoginThrottleRepository(db), config.AuthConfig{
+		LoginMaxAttempts:         3,
+		LoginIPMaxAttempts:       5,
+		PasswordResetMaxRequests: 2,
+		LoginAttemptWindow:       15 * time.Minute,
+		LoginLockoutBase:         30 * time.Second,
+		LoginLockoutMax:          time.Hour,
+	})
+}
+
+// errorCode returns the code of an AppError, empty for nil
+func errorCode(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) {
+		t.Fatalf("error = %v, want an AppError", err)
+	}
+	return appErr.Code
+}
+
+func TestLockoutFor(t *testing.T) {
+	s := &LoginThrottleService{baseLockout: 30 * time.Second, maxLockout: 5 * time.Minute}
+
+	tests := []struct {
+		excess int
+		want   time.Duration
+	}{
+		{excess: 0, want: 30 * time.Second},
+		{excess: 1, want: time.Minute},
+		{excess: 2, want: 2 * time.Minute},
+		{excess: 3, want: 4 * time.Minute},
+		{excess: 4, want: 5 * time.Minute},
+		{excess: 100, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := s.lockoutFor(tt.excess); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", tt.excess, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	const (
+		email = "a@example.com"
+		ip    = "203.0.113.7"
+	)
+
+	tests := []struct {
+		name string
+		// record runs before the checks
+		record        func(t *testing.T, s *LoginThrottleService)
+		wantLogin     string // error code of CheckLogin(email, ip)
+		wantOtherIP   string // error code of CheckLogin(email, another ip)
+		wantOtherUser string // error code of CheckLogin(another email, ip)
+	}{
+		{
+			name:   "below the limit",
+			record: failLogins(email, ip, 2),
+		},
+		{
+			name:        "email locked at the limit",
+			record:      failLogins(email, ip, 3),
+			wantLogin:   errors.ErrCodeAuthAccountLocked,
+			wantOtherIP: errors.ErrCodeAuthAccountLocked,
+		},
+		{
+			name: "IP locked across emails",
+			record: func(t *testing.T, s *LoginThrottleService) {
+				for i, other := range []string{"b@example.com", "c@example.com", "d@example.com", "e@example.com", "f@example.com"} {
+					if err := s.RecordLoginFailure(other, ip); err != nil {
+						t.Fatalf("RecordLoginFailure(%d) error = %v", i, err)
+					}
+				}
+			},
+			wantLogin:     errors.ErrCodeAuthTooManyAttempts,
+			wantOtherUser: errors.ErrCodeAuthTooManyAttempts,
+		},
+		{
+			name: "success clears the email but not the IP",
+			record: func(t *testing.T, s *LoginThrottleService) {
+				failLogins(email, ip, 2)(t, s)
+				if err := s.RecordLoginSuccess(email); err != nil {
+					t.Fatalf("RecordLoginSuccess() error = %v", err)
+				}
+				failLogins(email, ip, 2)(t, s)
+				failLogins("b@example.com", ip, 1)(t, s)
+			},
+			wantLogin:     errors.ErrCodeAuthTooManyAttempts,
+			wantOtherUser: errors.ErrCodeAuthTooManyAttempts,
+		},
+		{
+			name: "unlock clears the email and the IP",
+			record: func(t *testing.T, s *LoginThrottleService) {
+				failLogins(email, ip, 5)(t, s)
+				if err := s.Unlock(7, email, ip); err != nil {
+					t.Fatalf("Unlock() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "password reset requests don't lock the login",
+			record: func(t *testing.T, s *LoginThrottleService) {
+				for i := 0; i < 5; i++ {
+					if err := s.RecordPasswordReset(email, ip); err != nil {
+						t.Fatalf("RecordPasswordReset() error = %v", err)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestThrottleService(t)
+			tt.record(t, s)
+
+			if got := errorCode(t, s.CheckLogin(email, ip)); got != tt.wantLogin {
+				t.Errorf("CheckLogin() = %q, want %q", got, tt.wantLogin)
+			}
+			if got := errorCode(t, s.CheckLogin(email, "198.51.100.1")); got != tt.wantOtherIP {
+				t.Errorf("CheckLogin(another IP) = %q, want %q", got, tt.wantOtherIP)
+			}
+			if got := errorCode(t, s.CheckLogin("z@example.com", ip)); got != tt.wantOtherUser {
+				t.Errorf("CheckLogin(another email) = %q, want %q", got, tt.wantOtherUser)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleLockoutDoubles(t *testing.T) {
+	s := newTestThrottleService(t)
+	const email = "a@example.com"
+
+	// Every failure past the limit doubles the lockout
+	failLogins(email, "203.0.113.7", 2)(t, s)
+	var previous time.Duration
+	for failures := 3; failures <= 6; failures++ {
+		failLogins(email, "203.0.113.7", 1)(t, s)
+
+		throttles, err := s.throttleRepo.GetByKeys([]string{emailKey(email)})
+		if err != nil || len(throttles) != 1 {
+			t.Fatalf("GetByKeys() = %v, %v", throttles, err)
+		}
+		throttle := throttles[0]
+		lockout := throttle.LockedUntil.Sub(*throttle.LastFailureAt)
+
+		want := 30 * time.Second
+		if previous > 0 {
+			want = 2 * previous
+		}
+		if throttle.Failures != failures || lockout != want {
+			t.Errorf("after %d failures: failures = %d, lockout = %v, want %d, %v", failures, throttle.Failures, lockout, failures, want)
+		}
+		previous = lockout
+	}
+
+	err := s.CheckLogin(email, "198.51.100.1")
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) || appErr.Details["retry_after"] == nil {
+		t.Errorf("CheckLogin() error = %v, want retry_after in the details", err)
+	}
+}
+
+func TestPasswordResetThrottle(t *testing.T) {
+	s := newTestThrottleService(t)
+	const ip = "203.0.113.7"
+
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "a@example.com"},
+		{email: "a@example.com"},
+		{email: "A@example.com ", want: errors.ErrCodeAuthTooManyResets},
+		{email: "b@example.com"},
+		{email: "c@example.com"},
+		{email: "d@example.com"},
+		{email: "e@example.com", want: errors.ErrCodeAuthTooManyResets},
+	}
+
+	for i, tt := range tests {
+		if got := errorCode(t, s.CheckPasswordReset(tt.email, ip)); got != tt.want {
+			t.Fatalf("request %d: CheckPasswordReset(%q) = %q, want %q", i, tt.email, got, tt.want)
+		}
+		if tt.want == "" {
+			if err := s.RecordPasswordReset(tt.email, ip); err != nil {
+				t.Fatalf("RecordPasswordReset() error = %v", err)
+			}
+		}
+	}
+}
+
+// failLogins records count failed logins of the email from the IP
+func failLogins(email, ip string, count int) func(t *testing.T, s *LoginThrottleService) {
+	return func(t *testing.T, s *LoginThrottleService) {
+		t.Helper()
+		for i := 0; i < count; i++ {
+			if err := s.RecordLoginFailure(email, ip); err != nil {
+				t.Fatalf("RecordLoginFailure() error = %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	stdErrors "errors"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes handed out per enrollment
+const recoveryCodeCount = 10
+
+// MFAService manages TOTP enrollment, second-factor checks and recovery codes
+type MFAService struct {
+	mfaRepo *repository.MFARepository
+	issuer  string
+}
+
+func NewMFAService(mfaRepo *repository.MFARepository, cfg config.AuthConfig) *MFAService {
+	return &MFAService{
+		mfaRepo: mfaRepo,
+		issuer:  cfg.MFAIssuer,
+	}
+}
+
+// MFAStatus describes the second-factor state of a user
+type MFAStatus struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// IsEnabled reports whether the user has a confirmed TOTP enrollment
+func (s *MFAService) IsEnabled(userID uint) (bool, error) {
+	secret, err := s.getSecret(userID)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrAuthMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return secret.IsEnabled(), nil
+}
+
+// GetStatus returns whether MFA is enabled and how many recovery codes are left
+func (s *MFAService) GetStatus(userID uint) (*MFAStatus, error) {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return &MFAStatus{}, nil
+	}
+
+	left, err := s.mfaRepo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// Enroll starts a TOTP enrollment and returns the secret and its otpauth:// URI
+// The enrollment stays pending until Enable confirms a first code
+func (s *MFAService) Enroll(userID uint, accountName string) (string, string, error) {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", errors.ErrAuthMFAEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.mfaRepo.WithTx(tx).ReplacePendingSecret(userID, secret)
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, utils.TOTPURI(s.issuer, accountName, secret), nil
+}
+
+// Enable confirms a pending enrollment with a TOTP code and returns fresh recovery codes
+// The plain recovery codes are only returned here and never stored
+func (s *MFAService) Enable(userID uint, code string) ([]string, error) {
+	secret, err := s.getSecret(userID)
+	if err != nil {
+		return nil, err
+	}
+	if secret.IsEnabled() {
+		return nil, errors.ErrAuthMFAEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(secret.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.ErrAuthMFAInvalidCode
+	}
+
+	var codes []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.mfaRepo.WithTx(tx)
+		if err := repo.Enable(secret.ID, time.Now(), step); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(repo, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify checks a second factor: a TOTP code or an unused recovery code
+// Accepted TOTP codes and recovery codes can't be used again
+func (s *MFAService) Verify(userID uint, code string) error {
+	secret, err := s.getSecret(userID)
+	if err != nil {
+		return err
+	}
+	if !secret.IsEnabled() {
+		return errors.ErrAuthMFANotEnrolled
+	}
+
+	if step, ok := utils.ValidateTOTP(secret.Secret, code, time.Now()); ok {
+		fresh, err := s.mfaRepo.AdvanceLastUsedStep(secret.ID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errors.ErrAuthMFAInvalidCode
+		}
+		return nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(userID, utils.HashToken(utils.NormalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.ErrAuthMFAInvalidCode
+	}
+	return nil
+}
+
+// Disable removes the TOTP enrollment after checking a second factor
+func (s *MFAService) Disable(userID uint, code string) error {
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.mfaRepo.WithTx(tx).DeleteForUser(userID)
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a second factor
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(s.mfaRepo.WithTx(tx), userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *MFAService) getSecret(userID uint) (*model.MFASecret, error) {
+	secret, err := s.mfaRepo.GetSecretByUserID(userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAuthMFANotEnrolled
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(repo *repository.MFARepository, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+
+	if err := repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
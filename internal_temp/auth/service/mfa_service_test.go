@@ -0,0 +1,101 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/database"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+)
+
+// totpAt computes the code an authenticator app shows for the secret at the given time
+func totpAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestMFAVerify(t *testing.T) {
+	const userID = 7
+
+	db := databasetest.Open(t, &model.MFASecret{}, &model.MFARecoveryCode{})
+	database.DB = db
+	s := NewMFAService(repository.NewMFARepository(db), config.AuthConfig{MFAIssuer: "nogo"})
+
+	if err := s.Verify(userID, "000000"); !stdErrors.Is(err, errors.ErrAuthMFANotEnrolled) {
+		t.Fatalf("Verify() before enrolling error = %v, want %v", err, errors.ErrAuthMFANotEnrolled)
+	}
+
+	secret, _, err := s.Enroll(userID, "a@example.com")
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	now := time.Now()
+	if err := s.Verify(userID, totpAt(t, secret, now)); !stdErrors.Is(err, errors.ErrAuthMFANotEnrolled) {
+		t.Fatalf("Verify() with a pending enrollment error = %v, want %v", err, errors.ErrAuthMFANotEnrolled)
+	}
+
+	recoveryCodes, err := s.Enable(userID, totpAt(t, secret, now))
+	if err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("Enable() recovery codes = %d, want %d", len(recoveryCodes), recoveryCodeCount)
+	}
+
+	// The steps run in order, each one sees the codes used by the ones before
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "code used to enable is replayed", code: totpAt(t, secret, now), wantErr: errors.ErrAuthMFAInvalidCode},
+		{name: "code of the next step", code: totpAt(t, secret, now.Add(30*time.Second))},
+		{name: "same code again is replayed", code: totpAt(t, secret, now.Add(30*time.Second)), wantErr: errors.ErrAuthMFAInvalidCode},
+		{name: "code of an earlier step after a later one", code: totpAt(t, secret, now.Add(-30*time.Second)), wantErr: errors.ErrAuthMFAInvalidCode},
+		{name: "wrong code", code: "abcdef", wantErr: errors.ErrAuthMFAInvalidCode},
+		{name: "recovery code", code: recoveryCodes[0]},
+		{name: "recovery code used again", code: recoveryCodes[0], wantErr: errors.ErrAuthMFAInvalidCode},
+		{name: "recovery code typed without dash in uppercase", code: strings.ToUpper(strings.ReplaceAll(recoveryCodes[1], "-", ""))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Verify(userID, tt.code); !stdErrors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	status, err := s.GetStatus(userID)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.Enabled || status.RecoveryCodesLeft != recoveryCodeCount-2 {
+		t.Errorf("GetStatus() = %+v, want enabled with %d recovery codes left", status, recoveryCodeCount-2)
+	}
+}
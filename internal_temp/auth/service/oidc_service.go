@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	stdErrors "errors"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/oidc"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	"gorm.io/gorm"
+)
+
+// OIDCService runs the OAuth2 / OpenID Connect authorization code flow with PKCE
+// and keeps track of the external identities linked to users
+type OIDCService struct {
+	identityRepo *repository.UserIdentityRepository
+	providers    map[string]*oidc.Provider
+	stateTTL     time.Duration
+	redirectBase *url.URL // callbacks are <redirectBase>/<provider>/callback
+}
+
+// StateCookieName is the cookie binding a login to the browser that started it
+const StateCookieName = "oidc_state"
+
+func NewOIDCService(identityRepo *repository.UserIdentityRepository, cfg config.OIDCConfig) *OIDCService {
+	providers := make(map[string]*oidc.Provider, len(cfg.Providers))
+	for _, providerCfg := range cfg.Providers {
+		redirectURL := cfg.RedirectBaseURL + "/" + providerCfg.Name + "/callback"
+		providers[providerCfg.Name] = oidc.NewProvider(providerCfg, redirectURL)
+	}
+
+	redirectBase, err := url.Parse(cfg.RedirectBaseURL)
+	if err != nil {
+		log.Printf("Invalid OIDC redirect base URL %q: %v", cfg.RedirectBaseURL, err)
+		redirectBase = &url.URL{}
+	}
+
+	return &OIDCService{
+		identityRepo: identityRepo,
+		providers:    providers,
+		stateTTL:     cfg.StateTTL,
+		redirectBase: redirectBase,
+	}
+}
+
+// ProviderNames lists the configured providers
+func (s *OIDCService) ProviderNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthorizationURL starts a login at the provider and returns the URL to send the user to and the state
+// The state, nonce and PKCE verifier are kept server-side until the callback. The state must also be
+// given to the browser in StateCookie, so the callback is only accepted from the browser that started it
+func (s *OIDCService) AuthorizationURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", errors.ErrAuthOIDCProvider
+	}
+
+	state, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := utils.GenerateOpaqueToken(48)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	if err := s.identityRepo.DeleteExpiredStates(now); err != nil {
+		log.Printf("Failed to delete expired social login states: %v", err)
+	}
+
+	err = s.identityRepo.CreateState(&model.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(s.stateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		log.Printf("Social login with %s failed: %v", providerName, err)
+		return "", "", errors.ErrAuthOIDCFailed
+	}
+	return authURL, state, nil
+}
+
+// StateCookie returns the cookie holding the state of a login, sent back only to the provider's callback
+// SameSite Lax, so the browser sends it along the provider's redirect but not with requests from other sites
+func (s *OIDCService) StateCookie(providerName, state string) *http.Cookie {
+	return &http.Cookie{
+		Name:     StateCookieName,
+		Value:    state,
+		Path:     s.redirectBase.Path + "/" + providerName + "/callback",
+		MaxAge:   int(s.stateTTL.Seconds()),
+		Secure:   s.redirectBase.Scheme == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// ExpiredStateCookie returns a cookie removing the state cookie once the callback is done
+func (s *OIDCService) ExpiredStateCookie(providerName string) *http.Cookie {
+	cookie := s.StateCookie(providerName, "")
+	cookie.MaxAge = -1
+	return cookie
+}
+
+// Authenticate completes a login from the provider's callback and returns the external identity
+// browserState is the state from StateCookie, a callback with another state was started by someone else,
+// e.g. an attacker logging the browser in to their own account
+func (s *OIDCService) Authenticate(ctx context.Context, providerName, code, state, browserState string) (*oidc.Identity, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errors.ErrAuthOIDCProvider
+	}
+
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, errors.ErrAuthOIDCState
+	}
+
+	var loginState *model.OIDCLoginState
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		loginState, err = s.identityRepo.WithTx(tx).ConsumeState(utils.HashToken(state))
+		return err
+	})
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAuthOIDCState
+		}
+		return nil, err
+	}
+
+	if loginState.Provider != providerName || time.Now().After(loginState.ExpiresAt) {
+		return nil, errors.ErrAuthOIDCState
+	}
+
+	identity, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("Social login with %s failed: %v", providerName, err)
+		return nil, errors.ErrAuthOIDCFailed
+	}
+	return identity, nil
+}
+
+// FindLinkedUserID returns the user linked to an external identity
+// Returns 0 when the identity isn't linked yet
+func (s *OIDCService) FindLinkedUserID(tx *gorm.DB, identity *oidc.Identity) (uint, error) {
+	linked, err := s.identityRepo.WithTx(tx).GetByProviderSubject(identity.Provider, identity.Subject)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return linked.UserID, nil
+}
+
+// LinkIdentity links an external identity to a user
+func (s *OIDCService) LinkIdentity(tx *gorm.DB, userID uint, identity *oidc.Identity) error {
+	link := &model.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	}
+	if identity.Email != "" {
+		link.Email = &identity.Email
+	}
+	return s.identityRepo.WithTx(tx).Create(link)
+}
+
+// GetIdentities lists the external accounts linked to a user
+func (s *OIDCService) GetIdentities(userID uint) ([]model.UserIdentity, error) {
+	return s.identityRepo.GetByUserID(userID)
+}
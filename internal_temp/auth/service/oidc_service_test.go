@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/oidc/oidctest"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+	"gorm.io/gorm"
+)
+
+func TestOIDCAuthenticate(t *testing.T) {
+	mock := oidctest.NewMockProvider()
+	defer mock.Close()
+
+	// updateState changes the stored login state, as if it had been tampered with or had expired
+	updateState := func(column string, value any) func(t *testing.T, db *gorm.DB, state string) {
+		return func(t *testing.T, db *gorm.DB, state string) {
+			err := db.Model(&model.OIDCLoginState{}).
+				Where("state_hash = ?", utils.HashToken(state)).
+				Update(column, value).Error
+			if err != nil {
+				t.Fatalf("update login state: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		provider string // provider of the callback, the login starts at "mock"
+		// prepare runs between the redirect to the provider and the callback
+		prepare func(t *testing.T, db *gorm.DB, state string)
+		// state returns the state of the callback and of the browser's cookie, given the one from the provider
+		state func(state string) string
+		// browserState returns the state in the browser's cookie, given the one of the callback
+		browserState func(state string) string
+		replay       bool // complete the login once before the callback under test
+		wantErr      error
+	}{
+		{name: "valid login", provider: "mock"},
+		{name: "unknown state", provider: "mock", state: func(string) string { return "unknown" }, wantErr: errors.ErrAuthOIDCState},
+		{name: "replayed state", provider: "mock", replay: true, wantErr: errors.ErrAuthOIDCState},
+		{name: "expired state", provider: "mock", prepare: updateState("expires_at", time.Now().Add(-time.Minute)), wantErr: errors.ErrAuthOIDCState},
+		{name: "state of another provider", provider: "other", wantErr: errors.ErrAuthOIDCState},
+		{name: "no state cookie", provider: "mock", browserState: func(string) string { return "" }, wantErr: errors.ErrAuthOIDCState},
+		{name: "state cookie of another login", provider: "mock", browserState: func(string) string { return "other-login" }, wantErr: errors.ErrAuthOIDCState},
+		{name: "nonce mismatch", provider: "mock", prepare: updateState("nonce", "tampered"), wantErr: errors.ErrAuthOIDCFailed},
+		{name: "code verifier mismatch", provider: "mock", prepare: updateState("code_verifier", "tampered"), wantErr: errors.ErrAuthOIDCFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := databasetest.Open(t, &model.OIDCLoginState{}, &model.UserIdentity{})
+			database.DB = db
+
+			s := NewOIDCService(repository.NewUserIdentityRepository(db), config.OIDCConfig{
+				RedirectBaseURL: "http://localhost/auth/oidc",
+				StateTTL:        10 * time.Minute,
+				Providers:       []config.OIDCProviderConfig{mock.Config("mock"), mock.Config("other")},
+			})
+
+			authURL, issuedState, err := s.AuthorizationURL(ctx, "mock")
+			if err != nil {
+				t.Fatalf("AuthorizationURL() error = %v", err)
+			}
+			code, state, err := mock.Authorize(authURL)
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			if state != issuedState {
+				t.Fatalf("provider returned state %q, want %q", state, issuedState)
+			}
+
+			if tt.prepare != nil {
+				tt.prepare(t, db, state)
+			}
+			if tt.replay {
+				if _, err := s.Authenticate(ctx, "mock", code, state, state); err != nil {
+					t.Fatalf("first Authenticate() error = %v", err)
+				}
+			}
+			if tt.state != nil {
+				state = tt.state(state)
+			}
+			browserState := state
+			if tt.browserState != nil {
+				browserState = tt.browserState(state)
+			}
+
+			identity, err := s.Authenticate(ctx, tt.provider, code, state, browserState)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if identity.Provider != "mock" || identity.Subject != "mock-user-1" || identity.Email != "mock.user@example.com" {
+				t.Errorf("Authenticate() identity = %+v", identity)
+			}
+		})
+	}
+}
+
+func TestOIDCStateCookie(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirectBase string
+		wantPath     string
+		wantSecure   bool
+	}{
+		{name: "http", redirectBase: "http://localhost/auth/oidc", wantPath: "/auth/oidc/mock/callback"},
+		{name: "https", redirectBase: "https://app.example.com/api/v1/auth/oidc", wantPath: "/api/v1/auth/oidc/mock/callback", wantSecure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewOIDCService(nil, config.OIDCConfig{RedirectBaseURL: tt.redirectBase, StateTTL: 10 * time.Minute})
+
+			cookie := s.StateCookie("mock", "state")
+			if cookie.Name != StateCookieName || cookie.Value != "state" || cookie.Path != tt.wantPath {
+				t.Errorf("StateCookie() = %s=%s path %s, want %s=state path %s", cookie.Name, cookie.Value, cookie.Path, StateCookieName, tt.wantPath)
+			}
+			if cookie.Secure != tt.wantSecure || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.MaxAge != 600 {
+				t.Errorf("StateCookie() secure %v httpOnly %v sameSite %v maxAge %d", cookie.Secure, cookie.HttpOnly, cookie.SameSite, cookie.MaxAge)
+			}
+
+			expired := s.ExpiredStateCookie("mock")
+			if expired.Path != tt.wantPath || expired.Value != "" || expired.MaxAge >= 0 {
+				t.Errorf("ExpiredStateCookie() = path %s value %q maxAge %d", expired.Path, expired.Value, expired.MaxAge)
+			}
+		})
+	}
+}
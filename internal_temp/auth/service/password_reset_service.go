@@ -0,0 +1,95 @@
+package service
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/mailer"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"gorm.io/gorm"
+)
+
+// PasswordResetService issues password reset tokens and mails them
+type PasswordResetService struct {
+	tokenRepo *repository.PasswordResetTokenRepository
+	mailer    mailer.Mailer
+	ttl       time.Duration
+	linkURL   string
+}
+
+func NewPasswordResetService(
+	tokenRepo *repository.PasswordResetTokenRepository,
+	m mailer.Mailer,
+	cfg config.AuthConfig,
+) *PasswordResetService {
+	return &PasswordResetService{
+		tokenRepo: tokenRepo,
+		mailer:    m,
+		ttl:       cfg.PasswordResetTTL,
+		linkURL:   cfg.PasswordResetURL,
+	}
+}
+
+// SendReset issues a new reset token for the user and mails the link
+// Earlier pending tokens of the user are invalidated
+func (s *PasswordResetService) SendReset(userID uint, email string) error {
+	plainToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.tokenRepo.InvalidateForUser(userID, now); err != nil {
+		return err
+	}
+
+	token := &model.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return err
+	}
+
+	link := s.linkURL + "?token=" + url.QueryEscape(plainToken)
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not ask for this, you can ignore this email.",
+			link, s.ttl,
+		),
+	})
+}
+
+// ConsumeToken validates a reset token and marks it as used
+// Must be called inside a transaction; the caller updates the password in the same tx
+func (s *PasswordResetService) ConsumeToken(tx *gorm.DB, plainToken string) (*model.PasswordResetToken, error) {
+	repo := s.tokenRepo.WithTx(tx)
+
+	token, err := repo.GetByHashForUpdate(utils.HashToken(plainToken))
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAuthResetInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, errors.ErrAuthResetInvalid
+	}
+
+	if err := repo.MarkUsed(token.ID, now); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
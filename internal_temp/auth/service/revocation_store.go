@@ -0,0 +1,232 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	revocationStore     *RevocationStore
+	revocationStoreOnce sync.Once
+)
+
+// RevocationStore keeps revoked access tokens in memory so that
+// AuthMiddleware can check them on every request without a query
+// The revoked_tokens table is the source of truth; the cache is filled on
+// start and kept in sync with rows written by other instances via Sync
+type RevocationStore struct {
+	repo *repository.RevokedTokenRepository
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time // jti -> expires at
+	sessions map[uint]time.Time   // session id -> expires at
+	cutoffs  map[uint]time.Time   // user id -> tokens issued before this are revoked
+	lastSync time.Time
+}
+
+// InitRevocationStore creates the singleton revocation store and loads active revocations
+func InitRevocationStore(db *gorm.DB) (*RevocationStore, error) {
+	var err error
+	revocationStoreOnce.Do(func() {
+		store := &RevocationStore{
+			repo:     repository.NewRevokedTokenRepository(db),
+			tokens:   make(map[string]time.Time),
+			sessions: make(map[uint]time.Time),
+			cutoffs:  make(map[uint]time.Time),
+		}
+
+		if syncErr := store.Sync(); syncErr != nil {
+			err = fmt.Errorf("failed to load revoked tokens: %w", syncErr)
+			return
+		}
+
+		revocationStore = store
+	})
+
+	return revocationStore, err
+}
+
+func GetRevocationStore() *RevocationStore {
+	return revocationStore
+}
+
+// IsRevoked reports whether the token described by the claims was revoked
+func (s *RevocationStore) IsRevoked(claims *utils.JWTClaims) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if claims.ID != "" {
+		if _, ok := s.tokens[claims.ID]; ok {
+			return true
+		}
+	}
+
+	if claims.SessionID != 0 {
+		if _, ok := s.sessions[claims.SessionID]; ok {
+			return true
+		}
+	}
+
+	if cutoff, ok := s.cutoffs[claims.UserID]; ok {
+		if claims.IssuedAt == nil || claims.IssuedAt.Before(cutoff) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RevokeToken revokes a single access token until it expires
+func (s *RevocationStore) RevokeToken(jti string, userID uint, expiresAt time.Time) error {
+	if err := s.repo.Create(&model.RevokedToken{
+		JTI:       &jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// ConsumeToken marks a single-use token as used until it expires
+// Reports false when it was used before, also by another instance
+func (s *RevocationStore) ConsumeToken(jti string, userID uint, expiresAt time.Time) (bool, error) {
+	consumed, err := s.repo.CreateUnique(&model.RevokedToken{
+		JTI:       &jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = expiresAt
+	s.mu.Unlock()
+	return consumed, nil
+}
+
+// RevokeSession revokes every access token issued for a login session
+// Access tokens of the session expire within TokenExpiration, after which the
+// revoked refresh token family keeps the session from being used
+func (s *RevocationStore) RevokeSession(sessionID, userID uint) error {
+	expiresAt := time.Now().Add(utils.TokenExpiration)
+
+	if err := s.repo.Create(&model.RevokedToken{
+		UserID:    userID,
+		SessionID: &sessionID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.sessions[sessionID] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeAllForUser revokes every access token a user was issued until now
+func (s *RevocationStore) RevokeAllForUser(userID uint) error {
+	// JWT timestamps have second precision. Tokens issued within the cutoff's second are kept,
+	// so a login right after it isn't revoked, see TokenService.RevokeAllForUser for the others
+	cutoff := time.Now().Truncate(time.Second)
+
+	if err := s.repo.Create(&model.RevokedToken{
+		UserID:       userID,
+		IssuedBefore: &cutoff,
+		ExpiresAt:    cutoff.Add(utils.TokenExpiration),
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.applyCutoff(userID, cutoff)
+	s.mu.Unlock()
+	return nil
+}
+
+// Sync loads revocations written since the last sync and drops expired ones
+func (s *RevocationStore) Sync() error {
+	now := time.Now()
+
+	s.mu.RLock()
+	// Overlap the window a little so rows committed late by other instances are not missed
+	since := s.lastSync.Add(-5 * time.Second)
+	s.mu.RUnlock()
+
+	revoked, err := s.repo.GetActiveSince(since, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range revoked {
+		if r.JTI != nil {
+			s.tokens[*r.JTI] = r.ExpiresAt
+		}
+		if r.SessionID != nil {
+			s.sessions[*r.SessionID] = r.ExpiresAt
+		}
+		if r.IssuedBefore != nil {
+			s.applyCutoff(r.UserID, *r.IssuedBefore)
+		}
+	}
+
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.After(now) {
+			delete(s.tokens, jti)
+		}
+	}
+	for sessionID, expiresAt := range s.sessions {
+		if !expiresAt.After(now) {
+			delete(s.sessions, sessionID)
+		}
+	}
+	for userID, cutoff := range s.cutoffs {
+		if !cutoff.Add(utils.TokenExpiration).After(now) {
+			delete(s.cutoffs, userID)
+		}
+	}
+
+	s.lastSync = now
+	return nil
+}
+
+// StartSync periodically syncs the cache and prunes expired rows in the background
+func (s *RevocationStore) StartSync(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.Sync(); err != nil {
+				log.Printf("Warning: Failed to sync revoked tokens: %v", err)
+				continue
+			}
+			if err := s.repo.DeleteExpired(time.Now()); err != nil {
+				log.Printf("Warning: Failed to prune revoked tokens: %v", err)
+			}
+		}
+	}()
+}
+
+// applyCutoff keeps the latest cutoff per user; caller must hold the write lock
+func (s *RevocationStore) applyCutoff(userID uint, cutoff time.Time) {
+	if current, ok := s.cutoffs[userID]; !ok || cutoff.After(current) {
+		s.cutoffs[userID] = cutoff
+	}
+}
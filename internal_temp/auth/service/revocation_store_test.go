@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRevokeAllForUser(t *testing.T) {
+	const userID = 7
+	s := newTestTokenService(t)
+
+	_, sessionID, err := s.StartSession(userID, ClientInfo{})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if err := s.RevokeAllForUser(userID); err != nil {
+		t.Fatalf("RevokeAllForUser() error = %v", err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		claims utils.JWTClaims
+		want   bool
+	}{
+		{name: "issued in an earlier second", claims: claimsIssuedAt(userID, 0, now.Add(-2*time.Second)), want: true},
+		{name: "issued by a revoked session", claims: claimsIssuedAt(userID, sessionID, now), want: true},
+		{name: "issued right after by a new session", claims: claimsIssuedAt(userID, sessionID+1, now), want: false},
+		{name: "without iat", claims: utils.JWTClaims{UserID: userID}, want: true},
+		{name: "another user", claims: claimsIssuedAt(userID+1, 0, now.Add(-2*time.Second)), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetRevocationStore().IsRevoked(&tt.claims); got != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// claimsIssuedAt returns claims as ValidateToken parses them, with iat in whole seconds
+func claimsIssuedAt(userID, sessionID uint, issuedAt time.Time) utils.JWTClaims {
+	return utils.JWTClaims{
+		UserID:           userID,
+		SessionID:        sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issuedAt.Truncate(time.Second))},
+	}
+}
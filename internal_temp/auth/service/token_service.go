@@ -0,0 +1,365 @@
+package service
+
+import (
+	stdErrors "errors"
+	"strings"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	"gorm.io/gorm"
+)
+
+// TokenService manages opaque refresh tokens, their rotation, the login
+// sessions they belong to and the server-side revocation of access tokens
+type TokenService struct {
+	refreshTokenRepo *repository.RefreshTokenRepository
+	sessionRepo      *repository.UserSessionRepository
+	revocationStore  *RevocationStore
+}
+
+// ClientInfo describes the device a login or refresh comes from
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// maxUserAgentLength matches the size of user_sessions.user_agent
+const maxUserAgentLength = 512
+
+func NewTokenService(refreshTokenRepo *repository.RefreshTokenRepository, sessionRepo *repository.UserSessionRepository) *TokenService {
+	return &TokenService{
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		revocationStore:  GetRevocationStore(),
+	}
+}
+
+// StartSession records a login session and issues the refresh token that starts its token family
+// Returns the plain token, which is only ever shown to the client, and the session ID for the access token
+func (s *TokenService) StartSession(userID uint, client ClientInfo) (string, uint, error) {
+	familyID, err := utils.GenerateOpaqueToken(24)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var (
+		refreshToken string
+		sessionID    uint
+	)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session, err := s.createSession(s.sessionRepo.WithTx(tx), userID, familyID, client, &now)
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = s.issue(s.refreshTokenRepo.WithTx(tx), userID, familyID)
+		if err != nil {
+			return err
+		}
+		sessionID = session.ID
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	return refreshToken, sessionID, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same family
+// and records the activity on its session
+// If the presented token was already used, it is treated as stolen and the whole
+// family and its session are revoked. A token revoked by logout is only invalid
+func (s *TokenService) RotateRefreshToken(plainToken string, client ClientInfo) (string, uint, uint, error) {
+	var (
+		newToken  string
+		userID    uint
+		sessionID uint
+		reused    bool
+	)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.refreshTokenRepo.WithTx(tx)
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		token, err := repo.GetByHashForUpdate(utils.HashToken(plainToken))
+		if err != nil {
+			if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrAuthRefreshInvalid
+			}
+			return err
+		}
+
+		now := time.Now()
+
+		session, err := sessionRepo.GetByFamilyID(token.FamilyID)
+		if err != nil && !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Reuse of a rotated token: revoke the whole family and commit that
+		if token.UsedAt != nil {
+			reused = true
+			if session != nil {
+				sessionID = session.ID
+				userID = session.UserID
+				if err := sessionRepo.Revoke(session.ID, now); err != nil {
+					return err
+				}
+			}
+			return repo.RevokeFamily(token.FamilyID, now)
+		}
+
+		if !token.IsActive(now) || (session != nil && session.RevokedAt != nil) {
+			return errors.ErrAuthRefreshInvalid
+		}
+
+		if err := repo.MarkUsed(token.ID, now); err != nil {
+			return err
+		}
+
+		newToken, err = s.issue(repo, token.UserID, token.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		// Token families issued before sessions were recorded get a session on their first rotation
+		if session == nil {
+			session, err = s.createSession(sessionRepo, token.UserID, token.FamilyID, client, nil)
+			if err != nil {
+				return err
+			}
+		} else {
+			userAgent := truncateUserAgent(client.UserAgent)
+			if err := sessionRepo.Touch(session.ID, userAgent, client.IP, now, now.Add(utils.RefreshTokenExpiration)); err != nil {
+				return err
+			}
+		}
+
+		userID = token.UserID
+		sessionID = session.ID
+		return nil
+	})
+
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if reused {
+		if sessionID != 0 {
+			if err := s.revocationStore.RevokeSession(sessionID, userID); err != nil {
+				return "", 0, 0, err
+			}
+		}
+		return "", 0, 0, errors.ErrAuthRefreshReused
+	}
+
+	return newToken, userID, sessionID, nil
+}
+
+// IsRecentLogin reports whether the user logged in for the session within the window
+// Refresh token rotations keep a session going but don't count as a login
+func (s *TokenService) IsRecentLogin(userID, sessionID uint, window time.Duration) (bool, error) {
+	session, err := s.sessionRepo.GetByIDAndUserID(sessionID, userID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := time.Now()
+	return session.IsActive(now) && session.AuthenticatedSince(now.Add(-window)), nil
+}
+
+// ListSessions lists the active login sessions of a user
+func (s *TokenService) ListSessions(userID uint) ([]model.UserSession, error) {
+	return s.sessionRepo.GetActiveByUserID(userID, time.Now())
+}
+
+// RevokeSession revokes a login session of the user: its refresh token family
+// and every access token issued for it
+func (s *TokenService) RevokeSession(userID, sessionID uint) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := s.sessionRepo.WithTx(tx).GetByIDAndUserID(sessionID, userID)
+		if err != nil {
+			if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrNotFound
+			}
+			return err
+		}
+		if session.RevokedAt != nil {
+			return errors.ErrNotFound
+		}
+
+		now := time.Now()
+		if err := s.sessionRepo.WithTx(tx).Revoke(session.ID, now); err != nil {
+			return err
+		}
+		return s.refreshTokenRepo.WithTx(tx).RevokeFamily(session.FamilyID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.revocationStore.RevokeSession(sessionID, userID)
+}
+
+// RevokeOtherSessions revokes every login session of the user except the current one
+func (s *TokenService) RevokeOtherSessions(userID, currentSessionID uint) error {
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := s.RevokeSession(userID, session.ID); err != nil && !stdErrors.Is(err, errors.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken revokes a single access token until it expires
+func (s *TokenService) RevokeAccessToken(claims *utils.JWTClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return errors.ErrAuthInvalidToken
+	}
+	return s.revocationStore.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
+
+// IsRevoked reports whether the token described by the claims was revoked or, for single-use tokens, used
+func (s *TokenService) IsRevoked(claims *utils.JWTClaims) bool {
+	return s.revocationStore.IsRevoked(claims)
+}
+
+// ConsumeMFAPendingToken marks a pending MFA token as used, so it completes a single login
+func (s *TokenService) ConsumeMFAPendingToken(claims *utils.JWTClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return errors.ErrAuthInvalidToken
+	}
+
+	consumed, err := s.revocationStore.ConsumeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.ErrAuthInvalidToken
+	}
+	return nil
+}
+
+// RevokeRefreshToken revokes the token family of a refresh token owned by the user
+// Unknown tokens or tokens of another user are ignored so logout never leaks token validity
+func (s *TokenService) RevokeRefreshToken(userID uint, plainToken string) error {
+	token, err := s.refreshTokenRepo.GetByHash(utils.HashToken(plainToken))
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if token.UserID != userID {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.refreshTokenRepo.RevokeFamily(token.FamilyID, now); err != nil {
+		return err
+	}
+
+	session, err := s.sessionRepo.GetByFamilyID(token.FamilyID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	if err := s.sessionRepo.Revoke(session.ID, now); err != nil {
+		return err
+	}
+	return s.revocationStore.RevokeSession(session.ID, userID)
+}
+
+// RevokeAllForUser revokes every session, refresh token and access token belonging to a user
+func (s *TokenService) RevokeAllForUser(userID uint) error {
+	now := time.Now()
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID, now)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllForUser(userID, now); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID, now); err != nil {
+		return err
+	}
+	if err := s.revocationStore.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+
+	// The cutoff keeps tokens issued within its second, those of the revoked sessions are revoked by session
+	for _, session := range sessions {
+		if err := s.revocationStore.RevokeSession(session.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TokenService) issue(repo *repository.RefreshTokenRepository, userID uint, familyID string) (string, error) {
+	plainToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenExpiration),
+	}
+	if err := repo.Create(token); err != nil {
+		return "", err
+	}
+
+	return plainToken, nil
+}
+
+// createSession records a session, authenticatedAt is the login time and nil when it wasn't started by a login
+func (s *TokenService) createSession(repo *repository.UserSessionRepository, userID uint, familyID string, client ClientInfo, authenticatedAt *time.Time) (*model.UserSession, error) {
+	now := time.Now()
+	session := &model.UserSession{
+		UserID:          userID,
+		FamilyID:        familyID,
+		UserAgent:       truncateUserAgent(client.UserAgent),
+		IP:              client.IP,
+		LastSeenAt:      now,
+		AuthenticatedAt: authenticatedAt,
+		ExpiresAt:       now.Add(utils.RefreshTokenExpiration),
+	}
+	if err := repo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > maxUserAgentLength {
+		// Cutting may split a multi-byte character, which the database would reject
+		return strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	return userAgent
+}
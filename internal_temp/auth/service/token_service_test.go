@@ -0,0 +1,168 @@
+package service
+
+import (
+	stdErrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/auth/model"
+	"github.com/FeisalDy/nogo/internal/auth/repository"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+)
+
+// newTestTokenService returns a token service on an empty in-memory database
+// The revocation store is package state, so every test starts a new one
+func newTestTokenService(t *testing.T) *TokenService {
+	t.Helper()
+
+	db := databasetest.Open(t, &model.RefreshToken{}, &model.UserSession{}, &model.RevokedToken{})
+	database.DB = db
+
+	revocationStore = nil
+	revocationStoreOnce = sync.Once{}
+	if _, err := InitRevocationStore(db); err != nil {
+		t.Fatalf("init revocation store: %v", err)
+	}
+	return NewTokenService(repository.NewRefreshTokenRepository(db), repository.NewUserSessionRepository(db))
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	const userID = 7
+	client := ClientInfo{UserAgent: "test", IP: "127.0.0.1"}
+
+	tests := []struct {
+		name string
+		// prepare returns the token to present, given the one issued at login
+		prepare func(t *testing.T, s *TokenService, token string) string
+		wantErr error
+		// wantSessionRevoked reports whether the session's access tokens end up revoked
+		wantSessionRevoked bool
+	}{
+		{
+			name:    "fresh token rotates",
+			prepare: func(t *testing.T, s *TokenService, token string) string { return token },
+		},
+		{
+			name: "reused token revokes the family and the session",
+			prepare: func(t *testing.T, s *TokenService, token string) string {
+				if _, _, _, err := s.RotateRefreshToken(token, client); err != nil {
+					t.Fatalf("first RotateRefreshToken() error = %v", err)
+				}
+				return token
+			},
+			wantErr:            errors.ErrAuthRefreshReused,
+			wantSessionRevoked: true,
+		},
+		{
+			name: "token revoked by logout is invalid",
+			prepare: func(t *testing.T, s *TokenService, token string) string {
+				if err := s.RevokeRefreshToken(userID, token); err != nil {
+					t.Fatalf("RevokeRefreshToken() error = %v", err)
+				}
+				return token
+			},
+			wantErr:            errors.ErrAuthRefreshInvalid,
+			wantSessionRevoked: true,
+		},
+		{
+			name:    "unknown token is invalid",
+			prepare: func(t *testing.T, s *TokenService, token string) string { return "unknown" },
+			wantErr: errors.ErrAuthRefreshInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTokenService(t)
+
+			token, sessionID, err := s.StartSession(userID, client)
+			if err != nil {
+				t.Fatalf("StartSession() error = %v", err)
+			}
+
+			newToken, gotUserID, gotSessionID, err := s.RotateRefreshToken(tt.prepare(t, s, token), client)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("RotateRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if newToken == "" || newToken == token {
+					t.Errorf("RotateRefreshToken() token = %q, want a new token", newToken)
+				}
+				if gotUserID != userID || gotSessionID != sessionID {
+					t.Errorf("RotateRefreshToken() = user %d session %d, want user %d session %d", gotUserID, gotSessionID, userID, sessionID)
+				}
+				// The new token belongs to the same family and rotates in turn
+				if _, _, _, err := s.RotateRefreshToken(newToken, client); err != nil {
+					t.Errorf("RotateRefreshToken(new token) error = %v", err)
+				}
+			}
+
+			revoked := GetRevocationStore().IsRevoked(&utils.JWTClaims{UserID: userID, SessionID: sessionID})
+			if revoked != tt.wantSessionRevoked {
+				t.Errorf("session revoked = %v, want %v", revoked, tt.wantSessionRevoked)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshTokenReuseRevokesLaterTokens(t *testing.T) {
+	s := newTestTokenService(t)
+	client := ClientInfo{}
+
+	token, _, err := s.StartSession(7, client)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	rotated, _, _, err := s.RotateRefreshToken(token, client)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+
+	// Whoever holds the rotated token loses it once the old one is presented again
+	if _, _, _, err := s.RotateRefreshToken(token, client); !stdErrors.Is(err, errors.ErrAuthRefreshReused) {
+		t.Fatalf("RotateRefreshToken(reused) error = %v, want %v", err, errors.ErrAuthRefreshReused)
+	}
+	if _, _, _, err := s.RotateRefreshToken(rotated, client); !stdErrors.Is(err, errors.ErrAuthRefreshInvalid) {
+		t.Errorf("RotateRefreshToken(rotated) error = %v, want %v", err, errors.ErrAuthRefreshInvalid)
+	}
+}
+
+func TestConsumeMFAPendingToken(t *testing.T) {
+	s := newTestTokenService(t)
+	if err := utils.InitJWTKeys(config.AuthConfig{JWTAlgorithm: "HS256", JWTSecret: "test-secret"}); err != nil {
+		t.Fatalf("InitJWTKeys() error = %v", err)
+	}
+
+	token, err := utils.GenerateMFAPendingToken(7)
+	if err != nil {
+		t.Fatalf("GenerateMFAPendingToken() error = %v", err)
+	}
+	claims, err := utils.ValidateMFAPendingToken(token)
+	if err != nil {
+		t.Fatalf("ValidateMFAPendingToken() error = %v", err)
+	}
+
+	if s.IsRevoked(claims) {
+		t.Fatal("IsRevoked() = true before the token was used")
+	}
+	if err := s.ConsumeMFAPendingToken(claims); err != nil {
+		t.Fatalf("ConsumeMFAPendingToken() error = %v", err)
+	}
+	if !s.IsRevoked(claims) {
+		t.Error("IsRevoked() = false after the token was used")
+	}
+	if err := s.ConsumeMFAPendingToken(claims); !stdErrors.Is(err, errors.ErrAuthInvalidToken) {
+		t.Errorf("ConsumeMFAPendingToken() again error = %v, want %v", err, errors.ErrAuthInvalidToken)
+	}
+
+	// An instance that hasn't synced the use yet still can't consume it again
+	GetRevocationStore().tokens = map[string]time.Time{}
+	if err := s.ConsumeMFAPendingToken(claims); !stdErrors.Is(err, errors.ErrAuthInvalidToken) {
+		t.Errorf("ConsumeMFAPendingToken() on another instance error = %v, want %v", err, errors.ErrAuthInvalidToken)
+	}
+}
@@ -0,0 +1,213 @@
+package casbin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FeisalDy/nogo/internal/common/model"
+	"github.com/FeisalDy/nogo/internal/common/repository"
+	"gorm.io/gorm"
+)
+
+// Audit sources, where a policy change was made from
+const (
+	AuditSourceAPI    = "api"
+	AuditSourceCLI    = "cli"
+	AuditSourceSystem = "system"
+)
+
+// Audit actions, one per policy change of CasbinService
+const (
+	AuditActionAddPermission        = "add_permission"
+	AuditActionRemovePermission     = "remove_permission"
+	AuditActionSetPermissions       = "set_permissions"
+	AuditActionAddPermissions       = "add_permissions"
+	AuditActionRemoveAllPermissions = "remove_all_permissions"
+	AuditActionAssignRole           = "assign_role"
+	AuditActionRemoveRole           = "remove_role"
+	AuditActionAssignDomainRole     = "assign_domain_role"
+	AuditActionRemoveDomainRole     = "remove_domain_role"
+	AuditActionDeleteRole           = "delete_role"
+	AuditActionRenameRole           = "rename_role"
+	AuditActionAddParentRole        = "add_parent_role"
+	AuditActionRemoveParentRole     = "remove_parent_role"
+	AuditActionClearPolicies        = "clear_policies"
+	AuditActionApplyPolicyFile      = "apply_policy_file"
+)
+
+// AuditTargetAll is the target of changes that may touch the rules of every role
+const AuditTargetAll = "*"
+
+// AuditActor identifies who changes the policies. The zero value is the system
+type AuditActor struct {
+	UserID    *uint  // nil when no user is signed in, e.g. the CLI or background jobs
+	Source    string // AuditSourceAPI, AuditSourceCLI or AuditSourceSystem
+	RequestID string
+}
+
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *CasbinService) WithActor(actor AuditActor) *CasbinService {
+	service := *s
+	service.actor = actor
+	return &service
+}
+
+// ruleScope lists the policy lines a change can touch, e.g. "p, admin, users, read"
+type ruleScope func(s *CasbinService) ([]string, error)
+
+// audit runs a policy change and records it in the authz audit log with the rules in scope before and
+// after it. Changes made while running it, e.g. RemoveAllPermissionsForRole within DeleteRole, are part of its entry.
+// The rules and the entry are written in one transaction, the caller's when it runs within Transaction.
+// Nothing is recorded when the change fails or leaves the rules unchanged
+func (s *CasbinService) audit(action, target string, scope ruleScope, change func(s *CasbinService) error) error {
+	if s.tx == nil {
+		return s.Transaction(func(_ *gorm.DB, casbin *CasbinService) error {
+			return casbin.audit(action, target, scope, change)
+		})
+	}
+	if s.auditing {
+		return change(s)
+	}
+
+	before, err := scope(s)
+	if err != nil {
+		return err
+	}
+
+	inner := *s
+	inner.auditing = true
+	if err := change(&inner); err != nil {
+		return err
+	}
+
+	after, err := scope(s)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(before, after) {
+		return nil
+	}
+
+	source := s.actor.Source
+	if source == "" {
+		source = AuditSourceSystem
+	}
+
+	entry := &model.AuthzAudit{
+		ActorID:     s.actor.UserID,
+		ActorSource: source,
+		RequestID:   s.actor.RequestID,
+		Action:      action,
+		Target:      target,
+		Before:      before,
+		After:       after,
+	}
+	if err := repository.NewAuthzAuditRepository(s.tx).Create(entry); err != nil {
+		return fmt.Errorf("failed to record authz audit entry: %w", err)
+	}
+	return nil
+}
+
+// roleRules is the scope of a role: its permissions and the roles it inherits from
+// The users and roles holding it are in their own scope, so changing a role doesn't record its members
+func roleRules(roleNames ...string) ruleScope {
+	return func(s *CasbinService) ([]string, error) {
+		var lines []string
+		for _, roleName := range roleNames {
+			policies, err := s.filteredRules("p", 0, roleName)
+			if err != nil {
+				return nil, err
+			}
+			parents, err := s.filteredRules("g", 0, roleName)
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, formatRules("p", policies)...)
+			lines = append(lines, formatRules("g", parents)...)
+		}
+		return sortedRules(lines), nil
+	}
+}
+
+// subjectRules is the scope of a user: the global and domain roles assigned to them
+func subjectRules(subject string) ruleScope {
+	return func(s *CasbinService) ([]string, error) {
+		roles, err := s.filteredRules("g", 0, subject)
+		if err != nil {
+			return nil, err
+		}
+		domainRoles, err := s.filteredRules("g2", 0, subject)
+		if err != nil {
+			return nil, err
+		}
+		return sortedRules(append(formatRules("g", roles), formatRules("g2", domainRoles)...)), nil
+	}
+}
+
+// policyFileRules is the scope of the policy file: role permissions and role inheritance
+func (s *CasbinService) policyFileRules() ([]string, error) {
+	policies, err := s.filteredRules("p", 0)
+	if err != nil {
+		return nil, err
+	}
+	groupingRules, err := s.filteredRules("g", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := formatRules("p", policies)
+	for _, rule := range groupingRules {
+		if len(rule) >= 2 && !isUserSubject(rule[0]) {
+			lines = append(lines, formatRule("g", rule))
+		}
+	}
+	return sortedRules(lines), nil
+}
+
+// allRules is the scope of every rule
+func (s *CasbinService) allRules() ([]string, error) {
+	lines, err := s.policyFileRules()
+	if err != nil {
+		return nil, err
+	}
+	groupingRules, err := s.filteredRules("g", 0)
+	if err != nil {
+		return nil, err
+	}
+	domainRules, err := s.filteredRules("g2", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range groupingRules {
+		if len(rule) >= 1 && isUserSubject(rule[0]) {
+			lines = append(lines, formatRule("g", rule))
+		}
+	}
+	lines = append(lines, formatRules("g2", domainRules)...)
+	return sortedRules(lines), nil
+}
+
+// formatRule formats a rule as a policy line, e.g. "g, user:3, admin"
+func formatRule(ptype string, rule []string) string {
+	return ptype + ", " + strings.Join(rule, ", ")
+}
+
+func formatRules(ptype string, rules [][]string) []string {
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, formatRule(ptype, rule))
+	}
+	return lines
+}
+
+// sortedRules sorts policy lines and drops duplicates, e.g. a role inheriting from itself listed twice
+func sortedRules(lines []string) []string {
+	if lines == nil {
+		return []string{}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
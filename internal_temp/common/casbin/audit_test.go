@@ -0,0 +1,212 @@
+package casbin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/FeisalDy/nogo/internal/common/model"
+	"gorm.io/gorm"
+)
+
+// addTestRules writes rules without auditing them, as the state before a change
+func addTestRules(t *testing.T, s *CasbinService, rules map[string][][]string) {
+	t.Helper()
+	err := s.Transaction(func(_ *gorm.DB, casbin *CasbinService) error {
+		for _, ptype := range []string{"p", "g", "g2"} {
+			if err := casbin.addRules(ptype, rules[ptype]...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("setup error = %v", err)
+	}
+}
+
+func TestAuditRecordsChanges(t *testing.T) {
+	actorID := uint(7)
+	apiActor := AuditActor{UserID: &actorID, Source: AuditSourceAPI, RequestID: "req-1"}
+
+	tests := []struct {
+		name       string
+		current    map[string][][]string // ptype -> rules before the change
+		actor      AuditActor
+		change     func(s *CasbinService) error
+		wantErr    bool // the change fails and nothing is recorded
+		wantAction string
+		wantTarget string
+		wantBefore []string
+		wantAfter  []string
+	}{
+		{
+			name:       "add permission",
+			change:     func(s *CasbinService) error { return s.AddPermissionForRole("editor", "novels", "read") },
+			wantAction: AuditActionAddPermission,
+			wantTarget: "editor",
+			wantBefore: []string{},
+			wantAfter:  []string{"p, editor, novels, read"},
+		},
+		{
+			name:       "remove permission lists the role's other rules",
+			current:    map[string][][]string{"p": {{"editor", "novels", "read"}, {"editor", "tags", "write"}}, "g": {{"editor", "member"}}},
+			change:     func(s *CasbinService) error { return s.RemovePermissionForRole("editor", "tags", "write") },
+			wantAction: AuditActionRemovePermission,
+			wantTarget: "editor",
+			wantBefore: []string{"g, editor, member", "p, editor, novels, read", "p, editor, tags, write"},
+			wantAfter:  []string{"g, editor, member", "p, editor, novels, read"},
+		},
+		{
+			name:    "set permissions",
+			current: map[string][][]string{"p": {{"editor", "novels", "read"}, {"editor", "tags", "write"}}},
+			change: func(s *CasbinService) error {
+				return s.SetPermissionsForRole("editor", [][]string{{"novels", "read"}, {"genres", "write"}})
+			},
+			wantAction: AuditActionSetPermissions,
+			wantTarget: "editor",
+			wantBefore: []string{"p, editor, novels, read", "p, editor, tags, write"},
+			wantAfter:  []string{"p, editor, genres, write", "p, editor, novels, read"},
+		},
+		{
+			name:       "add parent role",
+			current:    map[string][][]string{"p": {{"editor", "novels", "read"}}},
+			change:     func(s *CasbinService) error { return s.AddParentRole("editor", "member") },
+			wantAction: AuditActionAddParentRole,
+			wantTarget: "editor",
+			wantBefore: []string{"p, editor, novels, read"},
+			wantAfter:  []string{"g, editor, member", "p, editor, novels, read"},
+		},
+		{
+			name:       "assign role",
+			change:     func(s *CasbinService) error { return s.AssignRoleToUser(3, "editor") },
+			wantAction: AuditActionAssignRole,
+			wantTarget: "user:3",
+			wantBefore: []string{},
+			wantAfter:  []string{"g, user:3, editor"},
+		},
+		{
+			name:       "remove role lists the user's domain roles",
+			current:    map[string][][]string{"g": {{"user:3", "editor"}}, "g2": {{"user:3", "lead", "novel:12"}}},
+			actor:      apiActor,
+			change:     func(s *CasbinService) error { return s.RemoveRoleFromUser(3, "editor") },
+			wantAction: AuditActionRemoveRole,
+			wantTarget: "user:3",
+			wantBefore: []string{"g, user:3, editor", "g2, user:3, lead, novel:12"},
+			wantAfter:  []string{"g2, user:3, lead, novel:12"},
+		},
+		{
+			name:    "failed change",
+			current: map[string][][]string{"g": {{"member", "editor"}}},
+			change:  func(s *CasbinService) error { return s.AddParentRole("editor", "member") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestService(t)
+			addTestRules(t, s, tt.current)
+			s = s.WithActor(tt.actor)
+
+			if err := tt.change(s); (err != nil) != tt.wantErr {
+				t.Fatalf("change error = %v, want error %v", err, tt.wantErr)
+			}
+			// Repeating the change doesn't touch the rules, so it isn't recorded
+			if err := tt.change(s); (err != nil) != tt.wantErr {
+				t.Fatalf("repeated change error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var entries []model.AuthzAudit
+			if err := db.Find(&entries).Error; err != nil {
+				t.Fatalf("load audit entries: %v", err)
+			}
+			if tt.wantErr {
+				if len(entries) != 0 {
+					t.Fatalf("audit entries = %d, want none for a failed change", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("audit entries = %d, want 1", len(entries))
+			}
+
+			entry := entries[0]
+			wantSource := tt.actor.Source
+			if wantSource == "" {
+				wantSource = AuditSourceSystem
+			}
+			if entry.Action != tt.wantAction || entry.Target != tt.wantTarget {
+				t.Errorf("entry = %s on %s, want %s on %s", entry.Action, entry.Target, tt.wantAction, tt.wantTarget)
+			}
+			if entry.ActorSource != wantSource || entry.RequestID != tt.actor.RequestID ||
+				(entry.ActorID == nil) != (tt.actor.UserID == nil) || (entry.ActorID != nil && *entry.ActorID != *tt.actor.UserID) {
+				t.Errorf("entry actor = %v from %s in %q, want %v from %s in %q",
+					entry.ActorID, entry.ActorSource, entry.RequestID, tt.actor.UserID, wantSource, tt.actor.RequestID)
+			}
+			if !slices.Equal(entry.Before, tt.wantBefore) || !slices.Equal(entry.After, tt.wantAfter) {
+				t.Errorf("entry rules = %v -> %v, want %v -> %v", entry.Before, entry.After, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestRoleChangesAuditMembersSeparately(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *CasbinService) error
+		want   map[string][2][]string // target -> rules before and after
+	}{
+		{
+			name:   "rename",
+			change: func(s *CasbinService) error { return s.UpdateRoleName("editor", "writer") },
+			want: map[string][2][]string{
+				"editor":   {{"g, editor, member", "p, editor, novels, read"}, {"g, writer, member", "p, writer, novels, read"}},
+				"user:3":   {{"g, user:3, editor", "g2, user:3, editor, novel:12"}, {"g, user:3, writer", "g2, user:3, writer, novel:12"}},
+				"reviewer": {{"g, reviewer, editor"}, {"g, reviewer, writer"}},
+			},
+		},
+		{
+			name:   "delete",
+			change: func(s *CasbinService) error { return s.DeleteRole("editor") },
+			want: map[string][2][]string{
+				"editor":   {{"g, editor, member", "p, editor, novels, read"}, {}},
+				"user:3":   {{"g, user:3, editor", "g2, user:3, editor, novel:12"}, {}},
+				"reviewer": {{"g, reviewer, editor"}, {}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestService(t)
+
+			addTestRules(t, s, map[string][][]string{
+				"p":  {{"editor", "novels", "read"}},
+				"g":  {{"editor", "member"}, {"user:3", "editor"}, {"reviewer", "editor"}},
+				"g2": {{"user:3", "editor", "novel:12"}},
+			})
+
+			if err := tt.change(s); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+
+			var entries []model.AuthzAudit
+			if err := db.Find(&entries).Error; err != nil {
+				t.Fatalf("load audit entries: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("audit entries = %d, want %d", len(entries), len(tt.want))
+			}
+			for _, entry := range entries {
+				want, ok := tt.want[entry.Target]
+				if !ok {
+					t.Errorf("unexpected audit entry for %s", entry.Target)
+					continue
+				}
+				if !slices.Equal(entry.Before, want[0]) || !slices.Equal(entry.After, want[1]) {
+					t.Errorf("%s rules = %v -> %v, want %v -> %v", entry.Target, entry.Before, entry.After, want[0], want[1])
+				}
+			}
+		})
+	}
+}
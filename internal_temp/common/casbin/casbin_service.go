@@ -2,6 +2,8 @@ package casbin
 
 import (
 	"fmt"
+	"log"
+	"slices"
 	"strconv"
 	"sync"
 
@@ -12,11 +14,18 @@ import (
 )
 
 var (
-	enforcer     *casbin.Enforcer
+	enforcer     *casbin.SyncedEnforcer
 	enforcerOnce sync.Once
+
+	// transactionMu serializes policy changes, so they reach the enforcer in the order they were committed
+	transactionMu sync.Mutex
 )
 
-func InitCasbin(db *gorm.DB, modelPath string) (*casbin.Enforcer, error) {
+// DomainEnforceContext selects the r2/m2 definitions of the model, which check
+// domain roles (g2) in addition to global roles
+var DomainEnforceContext = casbin.EnforceContext{RType: "r2", PType: "p", EType: "e", MType: "m2"}
+
+func InitCasbin(db *gorm.DB, modelPath string) (*casbin.SyncedEnforcer, error) {
 	var err error
 	enforcerOnce.Do(func() {
 		adapter, adapterErr := gormadapter.NewAdapterByDB(db)
@@ -25,12 +34,18 @@ func InitCasbin(db *gorm.DB, modelPath string) (*casbin.Enforcer, error) {
 			return
 		}
 
-		enforcer, err = casbin.NewEnforcer(modelPath, adapter)
+		// Synced, so requests keep enforcing while the watcher reloads the policies
+		enforcer, err = casbin.NewSyncedEnforcer(modelPath, adapter)
 		if err != nil {
 			err = fmt.Errorf("failed to create casbin enforcer: %w", err)
 			return
 		}
 
+		// Rules are written by CasbinService within database transactions, the enforcer only
+		// holds them in memory and is updated once they are committed
+		enforcer.EnableAutoSave(false)
+		enforcer.EnableAutoNotifyWatcher(false)
+
 		if loadErr := enforcer.LoadPolicy(); loadErr != nil {
 			err = fmt.Errorf("failed to load policies: %w", loadErr)
 			return
@@ -40,13 +55,20 @@ func InitCasbin(db *gorm.DB, modelPath string) (*casbin.Enforcer, error) {
 	return enforcer, err
 }
 
-func GetEnforcer() *casbin.Enforcer {
+func GetEnforcer() *casbin.SyncedEnforcer {
 	return enforcer
 }
 
 type CasbinService struct {
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer
 	db       *gorm.DB
+	actor    AuditActor // recorded in the authz audit log, see WithActor
+	auditing bool       // set while an audited change runs, so the changes it makes aren't recorded twice
+
+	// Set on the service Transaction passes to its function
+	tx      *gorm.DB
+	adapter *gormadapter.Adapter // writes rules with tx
+	changes *[]ruleChange        // rules written with tx, applied to the enforcer once it is committed
 }
 
 func NewCasbinService(db *gorm.DB) *CasbinService {
@@ -56,52 +78,124 @@ func NewCasbinService(db *gorm.DB) *CasbinService {
 	}
 }
 
+// Transaction runs fc in a database transaction. fc makes its policy changes through the service it is
+// given, which writes the rules with tx, so they are committed or rolled back with the rows fc writes.
+// The enforcer is only updated once the transaction is committed: requests never see rules that could
+// still be rolled back, and a failed transaction leaves the enforcer as it was. Reads through the given
+// service include the changes made so far, Enforce doesn't until the commit.
+// Policy changes are serialized so they reach the enforcer in commit order, fc should only do the
+// database work, slow steps such as hashing a password belong before it.
+// Audit entries of the changes are written with tx, and other instances are told about the
+// changes once they are committed. Called on the service given to fc, it runs fc in the same transaction
+func (s *CasbinService) Transaction(fc func(tx *gorm.DB, casbin *CasbinService) error) error {
+	if s.tx != nil {
+		return fc(s.tx, s)
+	}
+
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
+
+	var changes []ruleChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The table already exists, so the adapter doesn't need to migrate it inside the transaction
+		bound := tx.Session(&gorm.Session{})
+		gormadapter.TurnOffAutoMigrate(bound)
+
+		adapter, err := gormadapter.NewAdapterByDB(bound)
+		if err != nil {
+			return fmt.Errorf("failed to bind casbin adapter to transaction: %w", err)
+		}
+
+		service := *s
+		service.tx = tx
+		service.adapter = adapter
+		service.changes = &changes
+		return fc(tx, &service)
+	})
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	if err := s.applyChanges(changes); err != nil {
+		// The rules are committed, so loading them brings the enforcer in line
+		log.Printf("Warning: Failed to apply committed Casbin rules, reloading policies: %v", err)
+		if err := s.enforcer.LoadPolicy(); err != nil {
+			return fmt.Errorf("failed to reload policies: %w", err)
+		}
+	}
+
+	notifyWatcher()
+	return nil
+}
+
 // === Role Permission Management ===
 
 // AddPermissionForRole adds a permission to a role
 // resource: e.g., "users", "novels", "chapters"
 // action: e.g., "read", "write", "delete"
 func (s *CasbinService) AddPermissionForRole(roleName, resource, action string) error {
-	_, err := s.enforcer.AddPolicy(roleName, resource, action)
-	if err != nil {
-		return errors.ErrCasbinPolicySaveFailed
-	}
-	return s.enforcer.SavePolicy()
+	return s.audit(AuditActionAddPermission, roleName, roleRules(roleName), func(s *CasbinService) error {
+		if err := s.addRules("p", []string{roleName, resource, action}); err != nil {
+			return errors.ErrCasbinPolicySaveFailed
+		}
+		return nil
+	})
 }
 
 // RemovePermissionForRole removes a permission from a role
 func (s *CasbinService) RemovePermissionForRole(roleName, resource, action string) error {
-	_, err := s.enforcer.RemovePolicy(roleName, resource, action)
-	if err != nil {
-		return errors.ErrCasbinPolicyRemoveFailed
-	}
-	return s.enforcer.SavePolicy()
+	return s.audit(AuditActionRemovePermission, roleName, roleRules(roleName), func(s *CasbinService) error {
+		if err := s.removeRules("p", []string{roleName, resource, action}); err != nil {
+			return errors.ErrCasbinPolicyRemoveFailed
+		}
+		return nil
+	})
 }
 
 func (s *CasbinService) GetPermissionsForRole(roleName string) ([][]string, error) {
-	return s.enforcer.GetFilteredPolicy(0, roleName)
+	return s.filteredRules("p", 0, roleName)
+}
+
+// HasPermissionForRole checks if a role has been granted a permission directly
+func (s *CasbinService) HasPermissionForRole(roleName, resource, action string) (bool, error) {
+	return s.hasRule("p", []string{roleName, resource, action})
+}
+
+// SetPermissionsForRole replaces all permissions of a role with the given [resource, action] pairs
+func (s *CasbinService) SetPermissionsForRole(roleName string, permissions [][]string) error {
+	return s.audit(AuditActionSetPermissions, roleName, roleRules(roleName), func(s *CasbinService) error {
+		if err := s.removeFilteredRules("p", 0, roleName); err != nil {
+			return errors.ErrCasbinPolicyRemoveFailed
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+		return s.AddPermissionsForRole(roleName, permissions)
+	})
 }
 
 // === User Role Assignment ===
 
 // AssignRoleToUser assigns a role to a user
 func (s *CasbinService) AssignRoleToUser(userID uint, roleName string) error {
-	userSubject := fmt.Sprintf("user:%d", userID)
-	_, err := s.enforcer.AddRoleForUser(userSubject, roleName)
-	if err != nil {
-		return fmt.Errorf("failed to assign role: %w", err)
-	}
-	return s.enforcer.SavePolicy()
+	userSubject := FormatUserSubject(userID)
+	return s.audit(AuditActionAssignRole, userSubject, subjectRules(userSubject), func(s *CasbinService) error {
+		if err := s.addRules("g", []string{userSubject, roleName}); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
 }
 
 // RemoveRoleFromUser removes a role from a user
 func (s *CasbinService) RemoveRoleFromUser(userID uint, roleName string) error {
-	userSubject := fmt.Sprintf("user:%d", userID)
-	_, err := s.enforcer.DeleteRoleForUser(userSubject, roleName)
-	if err != nil {
-		return fmt.Errorf("failed to remove role: %w", err)
-	}
-	return s.enforcer.SavePolicy()
+	userSubject := FormatUserSubject(userID)
+	return s.audit(AuditActionRemoveRole, userSubject, subjectRules(userSubject), func(s *CasbinService) error {
+		if err := s.removeRules("g", []string{userSubject, roleName}); err != nil {
+			return fmt.Errorf("failed to remove role: %w", err)
+		}
+		return nil
+	})
 }
 
 // GetRolesForUser returns all roles for a user
@@ -129,6 +223,67 @@ func (s *CasbinService) GetUsersForRole(roleName string) ([]uint, error) {
 	return userIDs, nil
 }
 
+// GetAllUserRoles returns the global roles assigned to every user, keyed by user ID
+func (s *CasbinService) GetAllUserRoles() (map[uint][]string, error) {
+	rules, err := s.filteredRules("g", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoles := make(map[uint][]string)
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		userID, err := GetUserIDFromSubject(rule[0])
+		if err != nil {
+			continue
+		}
+		userRoles[userID] = append(userRoles[userID], rule[1])
+	}
+	return userRoles, nil
+}
+
+// === Domain Role Assignment ===
+
+// AssignRoleInDomain assigns a role to a user within a domain only, e.g. "editor" on "novel:12"
+func (s *CasbinService) AssignRoleInDomain(userID uint, roleName, domain string) error {
+	userSubject := FormatUserSubject(userID)
+	return s.audit(AuditActionAssignDomainRole, userSubject, subjectRules(userSubject), func(s *CasbinService) error {
+		if err := s.addRules("g2", []string{userSubject, roleName, domain}); err != nil {
+			return fmt.Errorf("failed to assign domain role: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveRoleInDomain removes a role from a user within a domain
+func (s *CasbinService) RemoveRoleInDomain(userID uint, roleName, domain string) error {
+	userSubject := FormatUserSubject(userID)
+	return s.audit(AuditActionRemoveDomainRole, userSubject, subjectRules(userSubject), func(s *CasbinService) error {
+		if err := s.removeRules("g2", []string{userSubject, roleName, domain}); err != nil {
+			return fmt.Errorf("failed to remove domain role: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetRolesInDomain returns the roles a user has within a domain, not including global roles
+func (s *CasbinService) GetRolesInDomain(userID uint, domain string) ([]string, error) {
+	rules, err := s.filteredRules("g2", 0, FormatUserSubject(userID), "", domain)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) >= 2 {
+			roles = append(roles, rule[1])
+		}
+	}
+	return roles, nil
+}
+
 // === Permission Checking ===
 
 // Enforce checks if a user has permission to perform an action on a resource
@@ -137,100 +292,320 @@ func (s *CasbinService) Enforce(userID uint, resource, action string) (bool, err
 	return s.enforcer.Enforce(userSubject, resource, action)
 }
 
+// EnforceInDomain checks a permission within a domain, using both global and domain roles
+func (s *CasbinService) EnforceInDomain(userID uint, domain, resource, action string) (bool, error) {
+	return s.enforcer.Enforce(DomainEnforceContext, FormatUserSubject(userID), domain, resource, action)
+}
+
 // === Batch Operations ===
 
 // AddPermissionsForRole adds multiple permissions to a role at once
 func (s *CasbinService) AddPermissionsForRole(roleName string, permissions [][]string) error {
-	rules := make([][]string, len(permissions))
-	for i, perm := range permissions {
-		if len(perm) != 2 {
-			return fmt.Errorf("invalid permission format, expected [resource, action]")
+	return s.audit(AuditActionAddPermissions, roleName, roleRules(roleName), func(s *CasbinService) error {
+		rules := make([][]string, len(permissions))
+		for i, perm := range permissions {
+			if len(perm) != 2 {
+				return fmt.Errorf("invalid permission format, expected [resource, action]")
+			}
+			rules[i] = []string{roleName, perm[0], perm[1]}
 		}
-		rules[i] = []string{roleName, perm[0], perm[1]}
-	}
 
-	_, err := s.enforcer.AddPolicies(rules)
-	if err != nil {
-		return fmt.Errorf("failed to add permissions: %w", err)
-	}
-	return s.enforcer.SavePolicy()
+		if err := s.addRules("p", rules...); err != nil {
+			return fmt.Errorf("failed to add permissions: %w", err)
+		}
+		return nil
+	})
 }
 
 // RemoveAllPermissionsForRole removes all permissions for a role
 func (s *CasbinService) RemoveAllPermissionsForRole(roleName string) error {
-	_, err := s.enforcer.RemoveFilteredPolicy(0, roleName)
-	if err != nil {
-		return fmt.Errorf("failed to remove permissions: %w", err)
-	}
-	return s.enforcer.SavePolicy()
+	return s.audit(AuditActionRemoveAllPermissions, roleName, roleRules(roleName), func(s *CasbinService) error {
+		if err := s.removeFilteredRules("p", 0, roleName); err != nil {
+			return fmt.Errorf("failed to remove permissions: %w", err)
+		}
+		return nil
+	})
 }
 
 // === Role Management ===
 
 // DeleteRole removes a role and all its assignments
+// Each user or role holding it gets its own audit entry, the role's entry only lists its permissions and parents
 func (s *CasbinService) DeleteRole(roleName string) error {
-	// Remove all permissions for the role
-	if err := s.RemoveAllPermissionsForRole(roleName); err != nil {
-		return err
-	}
+	return s.Transaction(func(_ *gorm.DB, casbin *CasbinService) error {
+		// Remove all user-role assignments, role inheritance and domain assignments
+		if err := casbin.replaceMembers(AuditActionDeleteRole, roleName, ""); err != nil {
+			return err
+		}
 
-	// Remove all user-role assignments
-	_, err := s.enforcer.RemoveFilteredGroupingPolicy(1, roleName)
-	if err != nil {
-		return fmt.Errorf("failed to remove role assignments: %w", err)
-	}
+		return casbin.audit(AuditActionDeleteRole, roleName, roleRules(roleName), func(s *CasbinService) error {
+			// Remove all permissions for the role
+			if err := s.RemoveAllPermissionsForRole(roleName); err != nil {
+				return err
+			}
 
-	return s.enforcer.SavePolicy()
+			// Remove the roles this role inherits from
+			if err := s.removeFilteredRules("g", 0, roleName); err != nil {
+				return fmt.Errorf("failed to remove role inheritance: %w", err)
+			}
+			return nil
+		})
+	})
 }
 
 // UpdateRoleName updates a role name (requires removing and re-adding policies)
+// Permissions, inheritance, user assignments and domain assignments all move to the new name.
+// Each user or role holding it gets its own audit entry, the role's entry only lists its permissions and parents
 func (s *CasbinService) UpdateRoleName(oldRoleName, newRoleName string) error {
-	// Get all permissions for the old role
-	permissions, err := s.GetPermissionsForRole(oldRoleName)
+	return s.Transaction(func(_ *gorm.DB, casbin *CasbinService) error {
+		err := casbin.audit(AuditActionRenameRole, oldRoleName, roleRules(oldRoleName, newRoleName), func(s *CasbinService) error {
+			// Get all permissions for the old role
+			permissions, err := s.GetPermissionsForRole(oldRoleName)
+			if err != nil {
+				return fmt.Errorf("failed to get permissions for role: %w", err)
+			}
+
+			// Get the roles the old role inherits from
+			parents, err := s.GetParentRoles(oldRoleName)
+			if err != nil {
+				return fmt.Errorf("failed to get parent roles: %w", err)
+			}
+
+			// Remove the old role's permissions and inheritance
+			if err := s.RemoveAllPermissionsForRole(oldRoleName); err != nil {
+				return err
+			}
+			if err := s.removeFilteredRules("g", 0, oldRoleName); err != nil {
+				return fmt.Errorf("failed to remove role inheritance: %w", err)
+			}
+
+			// Add permissions with new role name
+			for _, perm := range permissions {
+				if len(perm) >= 3 {
+					if err := s.AddPermissionForRole(newRoleName, perm[1], perm[2]); err != nil {
+						return err
+					}
+				}
+			}
+
+			// Restore the roles the new role inherits from
+			for _, parent := range parents {
+				if err := s.addRules("g", []string{newRoleName, parent}); err != nil {
+					return fmt.Errorf("failed to restore role inheritance: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Reassign users, inheriting roles and domain members to the new role
+		return casbin.replaceMembers(AuditActionRenameRole, oldRoleName, newRoleName)
+	})
+}
+
+// replaceMembers moves the users and roles holding a role, globally or within a domain, to another role,
+// or removes them when newRoleName is empty. Each member's change is its own audit entry targeting the member
+func (s *CasbinService) replaceMembers(action, oldRoleName, newRoleName string) error {
+	// Get all users with the old role, and roles that inherit from it
+	members, err := s.filteredRules("g", 1, oldRoleName)
 	if err != nil {
-		return fmt.Errorf("failed to get permissions for role: %w", err)
+		return fmt.Errorf("failed to get users for role: %w", err)
 	}
 
-	// Get all users with the old role
-	users, err := s.enforcer.GetUsersForRole(oldRoleName)
+	// Get the domain assignments of the old role, e.g. a team role within a novel
+	domainMembers, err := s.filteredRules("g2", 1, oldRoleName)
 	if err != nil {
-		return fmt.Errorf("failed to get users for role: %w", err)
+		return fmt.Errorf("failed to get domain role assignments: %w", err)
 	}
 
-	// Remove the old role
-	if err := s.DeleteRole(oldRoleName); err != nil {
-		return err
+	var subjects []string
+	for _, rule := range append(slices.Clone(members), domainMembers...) {
+		if len(rule) >= 2 && rule[0] != oldRoleName && !slices.Contains(subjects, rule[0]) {
+			subjects = append(subjects, rule[0])
+		}
 	}
 
-	// Add permissions with new role name
-	for _, perm := range permissions {
-		if len(perm) >= 3 {
-			if err := s.AddPermissionForRole(newRoleName, perm[1], perm[2]); err != nil {
-				return err
+	for _, subject := range subjects {
+		scope := subjectRules(subject)
+		if !isUserSubject(subject) {
+			scope = roleRules(subject)
+		}
+
+		err := s.audit(action, subject, scope, func(s *CasbinService) error {
+			for _, rule := range members {
+				if rule[0] != subject {
+					continue
+				}
+				if err := s.removeRules("g", rule); err != nil {
+					return fmt.Errorf("failed to remove role assignments: %w", err)
+				}
+				if newRoleName == "" {
+					continue
+				}
+				if err := s.addRules("g", []string{subject, newRoleName}); err != nil {
+					return fmt.Errorf("failed to restore role assignments: %w", err)
+				}
 			}
+
+			for _, rule := range domainMembers {
+				if rule[0] != subject || len(rule) < 3 {
+					continue
+				}
+				if err := s.removeRules("g2", rule); err != nil {
+					return fmt.Errorf("failed to remove domain role assignments: %w", err)
+				}
+				if newRoleName == "" {
+					continue
+				}
+				if err := s.addRules("g2", []string{subject, newRoleName, rule[2]}); err != nil {
+					return fmt.Errorf("failed to restore domain role assignments: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// Reassign users to new role
-	for _, user := range users {
-		// Extract user ID from "user:123" format
-		var userID uint64
-		_, err := fmt.Sscanf(user, "user:%d", &userID)
-		if err == nil {
-			if err := s.AssignRoleToUser(uint(userID), newRoleName); err != nil {
-				return err
+// === Role Inheritance ===
+
+// AddParentRole makes a role inherit all permissions of a parent role, e.g. "admin" inherits "moderator"
+// Inheritance is stored as a g rule from the role to its parent, so Enforce follows it like a user's role
+// Returns ErrRoleParentCycle if the parent already inherits from the role
+func (s *CasbinService) AddParentRole(roleName, parentRole string) error {
+	return s.audit(AuditActionAddParentRole, roleName, roleRules(roleName), func(s *CasbinService) error {
+		ancestors, err := s.GetAncestorRoles(parentRole)
+		if err != nil {
+			return err
+		}
+		if parentRole == roleName || slices.Contains(ancestors, roleName) {
+			return errors.ErrRoleParentCycle
+		}
+
+		if err := s.addRules("g", []string{roleName, parentRole}); err != nil {
+			return fmt.Errorf("failed to add parent role: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveParentRole stops a role from inheriting a parent role
+func (s *CasbinService) RemoveParentRole(roleName, parentRole string) error {
+	return s.audit(AuditActionRemoveParentRole, roleName, roleRules(roleName), func(s *CasbinService) error {
+		if err := s.removeRules("g", []string{roleName, parentRole}); err != nil {
+			return fmt.Errorf("failed to remove parent role: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetParentRoles returns the roles a role inherits from directly
+func (s *CasbinService) GetParentRoles(roleName string) ([]string, error) {
+	rules, err := s.filteredRules("g", 0, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) >= 2 {
+			parents = append(parents, rule[1])
+		}
+	}
+	return parents, nil
+}
+
+// GetAncestorRoles returns every role a role inherits from, directly or through other roles,
+// nearest first. The role itself is not included
+func (s *CasbinService) GetAncestorRoles(roleName string) ([]string, error) {
+	visited := map[string]bool{roleName: true}
+	queue := []string{roleName}
+	ancestors := []string{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		parents, err := s.GetParentRoles(current)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range parents {
+			if !visited[parent] {
+				visited[parent] = true
+				ancestors = append(ancestors, parent)
+				queue = append(queue, parent)
 			}
 		}
 	}
+	return ancestors, nil
+}
 
-	return s.enforcer.SavePolicy()
+// EffectivePermission is a permission together with the role that grants it
+type EffectivePermission struct {
+	Resource string
+	Action   string
+	Source   string
+}
+
+// GetEffectivePermissions returns the permissions of the given roles including inherited ones
+// Each permission is listed once, with the nearest role that grants it as its source
+func (s *CasbinService) GetEffectivePermissions(roleNames []string) ([]EffectivePermission, error) {
+	roles := make([]string, 0, len(roleNames))
+	seenRoles := make(map[string]bool)
+	for _, roleName := range roleNames {
+		if !seenRoles[roleName] {
+			seenRoles[roleName] = true
+			roles = append(roles, roleName)
+		}
+	}
+	// Direct roles come first, so their permissions win over inherited ones
+	for _, roleName := range roleNames {
+		ancestors, err := s.GetAncestorRoles(roleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if !seenRoles[ancestor] {
+				seenRoles[ancestor] = true
+				roles = append(roles, ancestor)
+			}
+		}
+	}
+
+	permissions := []EffectivePermission{}
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		policies, err := s.GetPermissionsForRole(role)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			if len(policy) < 3 {
+				continue
+			}
+			key := policy[1] + ":" + policy[2]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			permissions = append(permissions, EffectivePermission{Resource: policy[1], Action: policy[2], Source: role})
+		}
+	}
+	return permissions, nil
 }
 
 // === Utility Functions ===
 
 // GetAllRoles returns all unique roles in the system
+// Roles come from permissions and from g rules, so a role with no permissions of its own is included
 func (s *CasbinService) GetAllRoles() ([]string, error) {
-	allPolicies, err := s.enforcer.GetPolicy()
+	allPolicies, err := s.filteredRules("p", 0)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +617,21 @@ func (s *CasbinService) GetAllRoles() ([]string, error) {
 		}
 	}
 
+	groupingPolicies, err := s.filteredRules("g", 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range groupingPolicies {
+		if len(rule) < 2 {
+			continue
+		}
+		// Users are subjects, not roles
+		if !isUserSubject(rule[0]) {
+			roleSet[rule[0]] = true
+		}
+		roleSet[rule[1]] = true
+	}
+
 	roles := make([]string, 0, len(roleSet))
 	for role := range roleSet {
 		roles = append(roles, role)
@@ -263,13 +653,21 @@ func (s *CasbinService) GetAllSubjects() []string {
 
 // ReloadPolicies reloads all policies from the database
 func (s *CasbinService) ReloadPolicies() error {
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
 	return s.enforcer.LoadPolicy()
 }
 
 // ClearAllPolicies removes all policies (use with caution!)
 func (s *CasbinService) ClearAllPolicies() error {
-	s.enforcer.ClearPolicy()
-	return s.enforcer.SavePolicy()
+	return s.audit(AuditActionClearPolicies, AuditTargetAll, (*CasbinService).allRules, func(s *CasbinService) error {
+		for _, ptype := range []string{"p", "g", "g2"} {
+			if err := s.removeFilteredRules(ptype, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // === Sync with Database ===
@@ -279,7 +677,7 @@ func (s *CasbinService) ClearAllPolicies() error {
 func (s *CasbinService) SyncRolePermissions() error {
 	// This function can be called periodically or after database changes
 	// to ensure Casbin is in sync with the database
-	return s.enforcer.LoadPolicy()
+	return s.ReloadPolicies()
 }
 
 // GetUserIDFromSubject extracts user ID from subject string "user:123"
@@ -292,6 +690,11 @@ func GetUserIDFromSubject(subject string) (uint, error) {
 	return uint(userID), nil
 }
 
+// FormatNovelDomain formats a novel ID to the domain string "novel:12"
+func FormatNovelDomain(novelID uint) string {
+	return "novel:" + strconv.FormatUint(uint64(novelID), 10)
+}
+
 // FormatUserSubject formats user ID to subject string "user:123"
 func FormatUserSubject(userID uint) string {
 	return "user:" + strconv.FormatUint(uint64(userID), 10)
@@ -0,0 +1,238 @@
+package casbin
+
+import (
+	stdErrors "errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/model"
+	"github.com/FeisalDy/nogo/internal/database/databasetest"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+)
+
+// newTestService returns a service on an empty in-memory database
+// The enforcer is package state, so every test starts a new one
+func newTestService(t *testing.T) (*CasbinService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t, &model.AuthzAudit{})
+
+	enforcer = nil
+	enforcerOnce = sync.Once{}
+	if _, err := InitCasbin(db, "../../../config/casbin/model.conf"); err != nil {
+		t.Fatalf("init casbin: %v", err)
+	}
+	return NewCasbinService(db), db
+}
+
+func countRows(t *testing.T, db *gorm.DB, value any) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(value).Count(&count).Error; err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+	return count
+}
+
+func TestTransaction(t *testing.T) {
+	errFailed := stdErrors.New("failed")
+
+	tests := []struct {
+		name       string
+		fail       bool
+		wantRole   bool
+		wantRules  int64
+		wantAudits int64
+	}{
+		{name: "commit applies the rules", wantRole: true, wantRules: 2, wantAudits: 2},
+		{name: "rollback leaves the enforcer as it was", fail: true, wantRole: false, wantRules: 0, wantAudits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestService(t)
+
+			err := s.Transaction(func(tx *gorm.DB, casbin *CasbinService) error {
+				if err := casbin.AddPermissionForRole("editor", "novels", "read"); err != nil {
+					return err
+				}
+				if err := casbin.AssignRoleToUser(3, "editor"); err != nil {
+					return err
+				}
+
+				// Reads through the transaction's service include its changes, Enforce doesn't until the commit
+				permissions, err := casbin.GetPermissionsForRole("editor")
+				if err != nil {
+					return err
+				}
+				if len(permissions) != 1 {
+					t.Errorf("permissions within transaction = %v, want 1", permissions)
+				}
+				if allowed, _ := s.Enforce(3, "novels", "read"); allowed {
+					t.Error("Enforce allowed a rule that isn't committed yet")
+				}
+
+				if tt.fail {
+					return errFailed
+				}
+				return nil
+			})
+			if tt.fail && !stdErrors.Is(err, errFailed) {
+				t.Fatalf("Transaction() error = %v, want %v", err, errFailed)
+			}
+			if !tt.fail && err != nil {
+				t.Fatalf("Transaction() error = %v", err)
+			}
+
+			allowed, err := s.Enforce(3, "novels", "read")
+			if err != nil {
+				t.Fatalf("Enforce() error = %v", err)
+			}
+			if allowed != tt.wantRole {
+				t.Errorf("Enforce() = %v, want %v", allowed, tt.wantRole)
+			}
+			if got := countRows(t, db, &gormadapter.CasbinRule{}); got != tt.wantRules {
+				t.Errorf("casbin_rule rows = %d, want %d", got, tt.wantRules)
+			}
+			if got := countRows(t, db, &model.AuthzAudit{}); got != tt.wantAudits {
+				t.Errorf("authz_audit rows = %d, want %d", got, tt.wantAudits)
+			}
+		})
+	}
+}
+
+func TestTransactionReloadKeepsCommittedRules(t *testing.T) {
+	s, _ := newTestService(t)
+
+	if err := s.AssignRoleToUser(3, "admin"); err != nil {
+		t.Fatalf("AssignRoleToUser() error = %v", err)
+	}
+	if err := s.ReloadPolicies(); err != nil {
+		t.Fatalf("ReloadPolicies() error = %v", err)
+	}
+
+	hasRole, err := s.HasRole(3, "admin")
+	if err != nil {
+		t.Fatalf("HasRole() error = %v", err)
+	}
+	if !hasRole {
+		t.Error("role assigned outside a transaction wasn't saved")
+	}
+}
+
+func TestAddParentRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		parents [][2]string // role and parent added before the one under test
+		role    string
+		parent  string
+		wantErr error
+	}{
+		{name: "first parent", role: "admin", parent: "moderator"},
+		{name: "second parent", parents: [][2]string{{"admin", "moderator"}}, role: "admin", parent: "editor"},
+		{name: "shared ancestor", parents: [][2]string{{"admin", "moderator"}, {"moderator", "member"}}, role: "admin", parent: "member"},
+		{name: "role as its own parent", role: "admin", parent: "admin", wantErr: errors.ErrRoleParentCycle},
+		{name: "direct cycle", parents: [][2]string{{"admin", "moderator"}}, role: "moderator", parent: "admin", wantErr: errors.ErrRoleParentCycle},
+		{name: "indirect cycle", parents: [][2]string{{"admin", "moderator"}, {"moderator", "member"}}, role: "member", parent: "admin", wantErr: errors.ErrRoleParentCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			for _, rule := range tt.parents {
+				if err := s.AddParentRole(rule[0], rule[1]); err != nil {
+					t.Fatalf("AddParentRole(%s, %s) error = %v", rule[0], rule[1], err)
+				}
+			}
+
+			err := s.AddParentRole(tt.role, tt.parent)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("AddParentRole() error = %v, want %v", err, tt.wantErr)
+			}
+
+			parents, err := s.GetParentRoles(tt.role)
+			if err != nil {
+				t.Fatalf("GetParentRoles() error = %v", err)
+			}
+			if added := slices.Contains(parents, tt.parent); added != (tt.wantErr == nil) {
+				t.Errorf("GetParentRoles(%s) = %v, parent %s added = %v", tt.role, parents, tt.parent, added)
+			}
+		})
+	}
+}
+
+func TestGetEffectivePermissions(t *testing.T) {
+	// admin -> moderator -> member, and editor -> member
+	setup := func(t *testing.T, s *CasbinService) {
+		t.Helper()
+		rules := []struct {
+			role        string
+			permissions [][]string
+		}{
+			{"member", [][]string{{"novels", "read"}, {"comments", "write"}}},
+			{"moderator", [][]string{{"comments", "delete"}, {"novels", "read"}}},
+			{"admin", [][]string{{"users", "write"}}},
+			{"editor", [][]string{{"novels", "write"}, {"comments", "delete"}}},
+		}
+		for _, rule := range rules {
+			if err := s.AddPermissionsForRole(rule.role, rule.permissions); err != nil {
+				t.Fatalf("AddPermissionsForRole(%s) error = %v", rule.role, err)
+			}
+		}
+		for _, rule := range [][2]string{{"admin", "moderator"}, {"moderator", "member"}, {"editor", "member"}} {
+			if err := s.AddParentRole(rule[0], rule[1]); err != nil {
+				t.Fatalf("AddParentRole(%s, %s) error = %v", rule[0], rule[1], err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string // "resource:action <- source", in order
+	}{
+		{
+			name:  "inherited permissions after direct ones, nearest source wins",
+			roles: []string{"admin"},
+			want:  []string{"users:write <- admin", "comments:delete <- moderator", "novels:read <- moderator", "comments:write <- member"},
+		},
+		{
+			name:  "direct roles win over ancestors of earlier roles",
+			roles: []string{"admin", "editor"},
+			want:  []string{"users:write <- admin", "novels:write <- editor", "comments:delete <- editor", "novels:read <- moderator", "comments:write <- member"},
+		},
+		{
+			name:  "ancestors are listed once",
+			roles: []string{"editor", "moderator"},
+			want:  []string{"novels:write <- editor", "comments:delete <- editor", "novels:read <- moderator", "comments:write <- member"},
+		},
+		{
+			name:  "duplicate roles",
+			roles: []string{"member", "member"},
+			want:  []string{"novels:read <- member", "comments:write <- member"},
+		},
+		{name: "role without permissions", roles: []string{"guest"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			setup(t, s)
+
+			permissions, err := s.GetEffectivePermissions(tt.roles)
+			if err != nil {
+				t.Fatalf("GetEffectivePermissions() error = %v", err)
+			}
+			got := make([]string, 0, len(permissions))
+			for _, permission := range permissions {
+				got = append(got, permission.Resource+":"+permission.Action+" <- "+permission.Source)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetEffectivePermissions(%v) = %v, want %v", tt.roles, got, tt.want)
+			}
+		})
+	}
+}
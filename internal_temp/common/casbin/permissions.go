@@ -0,0 +1,95 @@
+package casbin
+
+import "sort"
+
+// Permission actions
+const (
+	ActionRead   = "read"
+	ActionWrite  = "write"
+	ActionDelete = "delete"
+)
+
+// Ownership scopes, appended to an action for resources with owners
+// e.g. "write:own" only allows writing resources the user owns, "write:any" allows all of them
+const (
+	ScopeOwn = "own"
+	ScopeAny = "any"
+)
+
+// knownPermissions is the registry of resources and the actions that can be granted on them
+// A role can only be given a permission listed here, so a typo can't create a dead policy
+var knownPermissions = map[string][]string{
+	"users":    {ActionRead, ActionWrite, ActionDelete},
+	"novels":   {ActionRead, OwnAction(ActionWrite), AnyAction(ActionWrite), OwnAction(ActionDelete), AnyAction(ActionDelete)},
+	"chapters": {ActionRead, ActionWrite, ActionDelete},
+	"genres":   {ActionRead, ActionWrite, ActionDelete},
+	"tags":     {ActionRead, ActionWrite, ActionDelete},
+	"roles":    {ActionRead, ActionWrite},
+	"media":    {ActionRead, ActionWrite, ActionDelete},
+	"profile":  {ActionRead, ActionWrite},
+	"teams":    {ActionRead, ActionWrite},
+	"authz":    {ActionRead},
+}
+
+// teamRoles are the roles that can be assigned within a novel team
+// Their permissions are the role's normal policies, but only apply inside the novel's domain
+var teamRoles = []string{"lead", "editor", "translator"}
+
+// IsTeamRole reports whether the role can be assigned within a novel team
+func IsTeamRole(roleName string) bool {
+	for _, role := range teamRoles {
+		if role == roleName {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamRoles returns the roles that can be assigned within a novel team
+func TeamRoles() []string {
+	return append([]string(nil), teamRoles...)
+}
+
+// IsKnownPermission reports whether the action is registered for the resource
+func IsKnownPermission(resource, action string) bool {
+	for _, known := range knownPermissions[resource] {
+		if known == action {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnAction returns the action limited to resources the user owns, e.g. "write:own"
+func OwnAction(action string) string {
+	return action + ":" + ScopeOwn
+}
+
+// AnyAction returns the action on any resource regardless of owner, e.g. "write:any"
+func AnyAction(action string) string {
+	return action + ":" + ScopeAny
+}
+
+// KnownPermissions returns the registry as resource -> actions, with resources sorted
+func KnownPermissions() []ResourcePermissions {
+	resources := make([]string, 0, len(knownPermissions))
+	for resource := range knownPermissions {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	result := make([]ResourcePermissions, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, ResourcePermissions{
+			Resource: resource,
+			Actions:  append([]string(nil), knownPermissions[resource]...),
+		})
+	}
+	return result
+}
+
+// ResourcePermissions lists the actions registered for a resource
+type ResourcePermissions struct {
+	Resource string   `json:"resource"`
+	Actions  []string `json:"actions"`
+}
@@ -0,0 +1,315 @@
+package casbin
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FeisalDy/nogo/internal/common/errors"
+)
+
+// DefaultPolicyPath is where the policy file is kept, relative to the working directory
+var DefaultPolicyPath = filepath.Join("config", "casbin", "policy.csv")
+
+// PolicyFile is the declarative set of role permissions and role inheritance,
+// kept in Casbin's CSV format, plus the roles the file owns:
+//
+//	managed: role, other_role
+//	p, role, resource, action
+//	g, role, parent_role
+//
+// The file is the source of truth for its managed roles: apply resets their permissions and parents
+// to the file, and the API refuses to change them. Other roles it names are only seeded, apply adds
+// their missing rules and leaves the ones added through the API. Roles the file doesn't name and user
+// role assignments are managed through the API and left alone by apply
+type PolicyFile struct {
+	Managed     []string   // roles owned by the file, sorted
+	Policies    [][]string // [role, resource, action]
+	Inheritance [][]string // [role, parent_role]
+}
+
+// managedRoles are the roles the policy file manages, read once at startup by LoadManagedRoles
+var (
+	managedRoles   []string
+	managedRolesMu sync.RWMutex
+)
+
+// PolicyDiff lists the rules to add and remove so the Casbin rules match a policy file
+type PolicyDiff struct {
+	AddPolicies       [][]string `json:"add_policies"`
+	RemovePolicies    [][]string `json:"remove_policies"`
+	AddInheritance    [][]string `json:"add_inheritance"`
+	RemoveInheritance [][]string `json:"remove_inheritance"`
+}
+
+// IsEmpty reports whether the Casbin rules already match the policy file
+func (d *PolicyDiff) IsEmpty() bool {
+	return len(d.AddPolicies) == 0 && len(d.RemovePolicies) == 0 &&
+		len(d.AddInheritance) == 0 && len(d.RemoveInheritance) == 0
+}
+
+// Lines formats the diff as policy file lines prefixed with "+" or "-"
+func (d *PolicyDiff) Lines() []string {
+	var lines []string
+	for _, rule := range d.RemovePolicies {
+		lines = append(lines, "- p, "+strings.Join(rule, ", "))
+	}
+	for _, rule := range d.AddPolicies {
+		lines = append(lines, "+ p, "+strings.Join(rule, ", "))
+	}
+	for _, rule := range d.RemoveInheritance {
+		lines = append(lines, "- g, "+strings.Join(rule, ", "))
+	}
+	for _, rule := range d.AddInheritance {
+		lines = append(lines, "+ g, "+strings.Join(rule, ", "))
+	}
+	return lines
+}
+
+// LoadPolicyFile reads and validates a policy file
+// Permissions must be in the permission registry and inheritance must not contain cycles
+func LoadPolicyFile(path string) (*PolicyFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy file: %w", err)
+	}
+	defer f.Close()
+
+	file := &PolicyFile{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if roles, ok := strings.CutPrefix(line, "managed:"); ok {
+			for _, role := range strings.Split(roles, ",") {
+				role = strings.TrimSpace(role)
+				if role == "" {
+					return nil, fmt.Errorf("%s:%d: empty role in managed", path, lineNumber)
+				}
+				if isUserSubject(role) {
+					return nil, fmt.Errorf("%s:%d: users can't be managed by the policy file", path, lineNumber)
+				}
+				file.Managed = append(file.Managed, role)
+			}
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if slices.Contains(fields, "") {
+			return nil, fmt.Errorf("%s:%d: empty field", path, lineNumber)
+		}
+
+		key := strings.Join(fields, ",")
+		if seen[key] {
+			return nil, fmt.Errorf("%s:%d: duplicate rule", path, lineNumber)
+		}
+		seen[key] = true
+
+		switch fields[0] {
+		case "p":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("%s:%d: expected \"p, role, resource, action\"", path, lineNumber)
+			}
+			if !IsKnownPermission(fields[2], fields[3]) {
+				return nil, fmt.Errorf("%s:%d: unknown permission %s:%s", path, lineNumber, fields[2], fields[3])
+			}
+			file.Policies = append(file.Policies, fields[1:])
+		case "g":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("%s:%d: expected \"g, role, parent_role\"", path, lineNumber)
+			}
+			if isUserSubject(fields[1]) || isUserSubject(fields[2]) {
+				return nil, fmt.Errorf("%s:%d: user role assignments can't be declared in the policy file", path, lineNumber)
+			}
+			file.Inheritance = append(file.Inheritance, fields[1:])
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown rule type %q, expected p or g", path, lineNumber, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	if role := findInheritanceCycle(file.Inheritance); role != "" {
+		return nil, fmt.Errorf("%s: role %q inherits from itself", path, role)
+	}
+	sort.Strings(file.Managed)
+	file.Managed = slices.Compact(file.Managed)
+	return file, nil
+}
+
+// IsManaged reports whether the policy file owns a role
+func (f *PolicyFile) IsManaged(roleName string) bool {
+	_, found := slices.BinarySearch(f.Managed, roleName)
+	return found
+}
+
+// Roles returns every role named in the policy file, managed or not, sorted
+func (f *PolicyFile) Roles() []string {
+	roles := slices.Clone(f.Managed)
+	for _, rule := range f.Policies {
+		roles = append(roles, rule[0])
+	}
+	for _, rule := range f.Inheritance {
+		roles = append(roles, rule...)
+	}
+	sort.Strings(roles)
+	return slices.Compact(roles)
+}
+
+// LoadManagedRoles reads the roles the policy file at path manages, see IsFileManagedRole
+// Called once at startup, a changed managed: line takes effect when the server restarts
+func LoadManagedRoles(path string) error {
+	file, err := LoadPolicyFile(path)
+	if err != nil {
+		return err
+	}
+
+	managedRolesMu.Lock()
+	defer managedRolesMu.Unlock()
+	managedRoles = file.Managed
+	return nil
+}
+
+// IsFileManagedRole reports whether the policy file loaded by LoadManagedRoles manages a role
+// Apply resets the permissions and parents of these roles, so they can't be changed through the API
+func IsFileManagedRole(roleName string) bool {
+	managedRolesMu.RLock()
+	defer managedRolesMu.RUnlock()
+	_, found := slices.BinarySearch(managedRoles, roleName)
+	return found
+}
+
+// DiffPolicyFile compares the permissions and parents of the roles named in a policy file with Casbin
+// Rules of managed roles that the file doesn't list are removed, the other roles it names only get the
+// missing rules. Roles the file doesn't name, grouping rules of users and domain roles are left out
+func (s *CasbinService) DiffPolicyFile(file *PolicyFile) (*PolicyDiff, error) {
+	named := file.Roles()
+
+	allPolicies, err := s.filteredRules("p", 0)
+	if err != nil {
+		return nil, err
+	}
+	groupingRules, err := s.filteredRules("g", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies [][]string
+	for _, rule := range allPolicies {
+		if len(rule) >= 1 && slices.Contains(named, rule[0]) {
+			policies = append(policies, rule)
+		}
+	}
+
+	var inheritance [][]string
+	for _, rule := range groupingRules {
+		if len(rule) >= 2 && slices.Contains(named, rule[0]) {
+			inheritance = append(inheritance, rule[:2])
+		}
+	}
+
+	diff := &PolicyDiff{}
+	diff.AddPolicies, diff.RemovePolicies = diffRules(policies, file.Policies)
+	diff.AddInheritance, diff.RemoveInheritance = diffRules(inheritance, file.Inheritance)
+	diff.RemovePolicies = file.managedRules(diff.RemovePolicies)
+	diff.RemoveInheritance = file.managedRules(diff.RemoveInheritance)
+	return diff, nil
+}
+
+// managedRules returns the rules of the managed roles, rules start with the role
+func (f *PolicyFile) managedRules(rules [][]string) [][]string {
+	var managed [][]string
+	for _, rule := range rules {
+		if f.IsManaged(rule[0]) {
+			managed = append(managed, rule)
+		}
+	}
+	return managed
+}
+
+// ApplyPolicyDiff adds and removes the rules of a diff
+func (s *CasbinService) ApplyPolicyDiff(diff *PolicyDiff) error {
+	return s.audit(AuditActionApplyPolicyFile, AuditTargetAll, (*CasbinService).policyFileRules, func(s *CasbinService) error {
+		if err := s.removeRules("p", diff.RemovePolicies...); err != nil {
+			return errors.ErrCasbinPolicyRemoveFailed
+		}
+		if err := s.addRules("p", diff.AddPolicies...); err != nil {
+			return errors.ErrCasbinPolicySaveFailed
+		}
+		if err := s.removeRules("g", diff.RemoveInheritance...); err != nil {
+			return fmt.Errorf("failed to remove parent roles: %w", err)
+		}
+		if err := s.addRules("g", diff.AddInheritance...); err != nil {
+			return fmt.Errorf("failed to add parent roles: %w", err)
+		}
+		return nil
+	})
+}
+
+// diffRules returns the wanted rules that are missing from current, and the current rules that are not wanted
+func diffRules(current, wanted [][]string) (add, remove [][]string) {
+	currentKeys := make(map[string]bool, len(current))
+	for _, rule := range current {
+		currentKeys[strings.Join(rule, ",")] = true
+	}
+	wantedKeys := make(map[string]bool, len(wanted))
+	for _, rule := range wanted {
+		wantedKeys[strings.Join(rule, ",")] = true
+	}
+
+	for _, rule := range wanted {
+		if !currentKeys[strings.Join(rule, ",")] {
+			add = append(add, rule)
+		}
+	}
+	for _, rule := range current {
+		if !wantedKeys[strings.Join(rule, ",")] {
+			remove = append(remove, rule)
+		}
+	}
+	return add, remove
+}
+
+// findInheritanceCycle returns a role that inherits from itself, or "" if there is none
+func findInheritanceCycle(inheritance [][]string) string {
+	parents := make(map[string][]string)
+	for _, rule := range inheritance {
+		parents[rule[0]] = append(parents[rule[0]], rule[1])
+	}
+
+	for role := range parents {
+		visited := map[string]bool{}
+		queue := slices.Clone(parents[role])
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if current == role {
+				return role
+			}
+			if visited[current] {
+				continue
+			}
+			visited[current] = true
+			queue = append(queue, parents[current]...)
+		}
+	}
+	return ""
+}
+
+// isUserSubject reports whether a subject is a user, e.g. "user:12", rather than a role
+func isUserSubject(subject string) bool {
+	return strings.HasPrefix(subject, "user:")
+}
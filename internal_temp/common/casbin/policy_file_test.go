@@ -0,0 +1,192 @@
+package casbin
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FeisalDy/nogo/internal/common/model"
+	"gorm.io/gorm"
+)
+
+func writePolicyFile(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write policy file: %v", err)
+	}
+	return path
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{name: "valid", lines: []string{"# comment", "managed: admin", "p, admin, users, delete", "g, admin, moderator"}},
+		{name: "managed user", lines: []string{"managed: admin, user:3"}, wantErr: "users can't be managed"},
+		{name: "empty managed role", lines: []string{"managed: admin,"}, wantErr: "empty role in managed"},
+		{name: "unknown permission", lines: []string{"p, admin, users, fly"}, wantErr: "unknown permission users:fly"},
+		{name: "user assignment", lines: []string{"g, user:3, admin"}, wantErr: "user role assignments"},
+		{name: "duplicate rule", lines: []string{"p, admin, users, read", "p,admin,users,read"}, wantErr: "duplicate rule"},
+		{name: "inheritance cycle", lines: []string{"g, admin, moderator", "g, moderator, admin"}, wantErr: "inherits from itself"},
+		{name: "unknown rule type", lines: []string{"g2, user:3, editor, novel:1"}, wantErr: "unknown rule type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicyFile(writePolicyFile(t, tt.lines...))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("LoadPolicyFile() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("LoadPolicyFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyFileDiffAndApply(t *testing.T) {
+	// The file owns admin and curator, moderator is only seeded
+	file, err := LoadPolicyFile(writePolicyFile(t,
+		"managed: admin, curator",
+		"p, admin, users, delete",
+		"p, moderator, users, read",
+		"g, admin, moderator",
+	))
+	if err != nil {
+		t.Fatalf("LoadPolicyFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		current  map[string][][]string // ptype -> rules before the diff
+		wantDiff []string
+	}{
+		{
+			name:     "empty database gets the file",
+			wantDiff: []string{"+ p, admin, users, delete", "+ p, moderator, users, read", "+ g, admin, moderator"},
+		},
+		{
+			name: "stale rules of managed roles are removed",
+			current: map[string][][]string{
+				"p": {{"admin", "users", "delete"}, {"admin", "tags", "delete"}, {"moderator", "users", "read"}, {"curator", "tags", "write"}},
+				"g": {{"admin", "moderator"}, {"admin", "author"}, {"curator", "moderator"}},
+			},
+			wantDiff: []string{"- p, admin, tags, delete", "- p, curator, tags, write", "- g, admin, author", "- g, curator, moderator"},
+		},
+		{
+			name: "rules added to seeded roles are kept",
+			current: map[string][][]string{
+				"p": {{"admin", "users", "delete"}, {"moderator", "tags", "write"}},
+				"g": {{"admin", "moderator"}, {"moderator", "author"}},
+			},
+			wantDiff: []string{"+ p, moderator, users, read"},
+		},
+		{
+			name: "roles and users the file doesn't name are left alone",
+			current: map[string][][]string{
+				"p":  {{"admin", "users", "delete"}, {"moderator", "users", "read"}, {"editor", "tags", "write"}},
+				"g":  {{"admin", "moderator"}, {"editor", "moderator"}, {"user:3", "admin"}},
+				"g2": {{"user:3", "editor", "novel:1"}},
+			},
+			wantDiff: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestService(t)
+
+			err := s.Transaction(func(_ *gorm.DB, casbin *CasbinService) error {
+				for _, ptype := range []string{"p", "g", "g2"} {
+					if err := casbin.addRules(ptype, tt.current[ptype]...); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("setup error = %v", err)
+			}
+			before, err := s.allRules()
+			if err != nil {
+				t.Fatalf("allRules() error = %v", err)
+			}
+
+			diff, err := s.DiffPolicyFile(file)
+			if err != nil {
+				t.Fatalf("DiffPolicyFile() error = %v", err)
+			}
+			if got := diff.Lines(); !slices.Equal(got, tt.wantDiff) {
+				t.Fatalf("diff = %v, want %v", got, tt.wantDiff)
+			}
+
+			if err := s.ApplyPolicyDiff(diff); err != nil {
+				t.Fatalf("ApplyPolicyDiff() error = %v", err)
+			}
+
+			// Applying brings the rules in line with the file, so there's nothing left to apply
+			diff, err = s.DiffPolicyFile(file)
+			if err != nil {
+				t.Fatalf("DiffPolicyFile() after apply error = %v", err)
+			}
+			if !diff.IsEmpty() {
+				t.Errorf("diff after apply = %v, want empty", diff.Lines())
+			}
+
+			// Only the rules in the diff changed
+			after, err := s.allRules()
+			if err != nil {
+				t.Fatalf("allRules() error = %v", err)
+			}
+			for _, line := range before {
+				if !slices.Contains(after, line) && !slices.Contains(tt.wantDiff, "- "+line) {
+					t.Errorf("apply removed %q, which isn't in the diff", line)
+				}
+			}
+
+			var entries int64
+			if err := db.Model(&model.AuthzAudit{}).Where("action = ?", AuditActionApplyPolicyFile).Count(&entries).Error; err != nil {
+				t.Fatalf("count audit entries: %v", err)
+			}
+			if want := int64(min(len(tt.wantDiff), 1)); entries != want {
+				t.Errorf("apply audit entries = %d, want %d", entries, want)
+			}
+		})
+	}
+}
+
+func TestIsFileManagedRole(t *testing.T) {
+	t.Cleanup(func() { managedRoles = nil })
+
+	if err := LoadManagedRoles(writePolicyFile(t,
+		"managed: moderator, admin",
+		"managed: admin",
+		"p, admin, users, delete",
+		"p, author, novels, write:own",
+	)); err != nil {
+		t.Fatalf("LoadManagedRoles() error = %v", err)
+	}
+
+	tests := []struct {
+		role string
+		want bool
+	}{
+		{role: "admin", want: true},
+		{role: "moderator", want: true},
+		{role: "author"}, // named in the file, but only seeded
+		{role: "editor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			if got := IsFileManagedRole(tt.role); got != tt.want {
+				t.Errorf("IsFileManagedRole(%s) = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package casbin
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// RouteAccess is how a route is authorized
+type RouteAccess string
+
+const (
+	RouteAccessPublic        RouteAccess = "public"        // no authentication
+	RouteAccessAuthenticated RouteAccess = "authenticated" // any signed in user, the route only acts on their own account
+	RouteAccessPermission    RouteAccess = "permission"    // a permission, enforced with CasbinMiddleware
+	RouteAccessOwnership     RouteAccess = "ownership"     // "<action>:any", or "<action>:own" on owned resources, enforced with OwnershipMiddleware
+)
+
+// RoutePermission is the access declared for a route when it is registered
+type RoutePermission struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Access   RouteAccess `json:"access"`
+	Resource string      `json:"resource,omitempty"`
+	Action   string      `json:"action,omitempty"`
+}
+
+// Actions returns the actions a role can be granted to pass the route's check
+func (p RoutePermission) Actions() []string {
+	switch p.Access {
+	case RouteAccessPermission:
+		return []string{p.Action}
+	case RouteAccessOwnership:
+		return []string{AnyAction(p.Action), OwnAction(p.Action)}
+	default:
+		return nil
+	}
+}
+
+var (
+	routePermissions   = make(map[string]RoutePermission) // "METHOD path" -> permission
+	routePermissionsMu sync.RWMutex
+)
+
+// RegisterRoutePermission records the access declared for a route
+func RegisterRoutePermission(permission RoutePermission) {
+	routePermissionsMu.Lock()
+	defer routePermissionsMu.Unlock()
+	routePermissions[permission.Method+" "+permission.Path] = permission
+}
+
+// GetRoutePermission returns the access declared for a route
+func GetRoutePermission(method, path string) (RoutePermission, bool) {
+	routePermissionsMu.RLock()
+	defer routePermissionsMu.RUnlock()
+	permission, ok := routePermissions[method+" "+path]
+	return permission, ok
+}
+
+// RoutePermissions returns the access declared for every route, sorted by path and method
+func RoutePermissions() []RoutePermission {
+	routePermissionsMu.RLock()
+	result := make([]RoutePermission, 0, len(routePermissions))
+	for _, permission := range routePermissions {
+		result = append(result, permission)
+	}
+	routePermissionsMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// VerifyRoutePermission checks that the permission of a route is in the permission registry
+func VerifyRoutePermission(permission RoutePermission) error {
+	for _, action := range permission.Actions() {
+		if !IsKnownPermission(permission.Resource, action) {
+			return fmt.Errorf("%s %s requires unknown permission %s:%s", permission.Method, permission.Path, permission.Resource, action)
+		}
+	}
+	return nil
+}
+
+// GetRolesWithRoutePermission returns the roles granted directly one of the actions that pass the route's check
+// Users holding one of these roles, or a role inheriting from them, can use the route
+func (s *CasbinService) GetRolesWithRoutePermission(permission RoutePermission) ([]string, error) {
+	var roles []string
+	for _, action := range permission.Actions() {
+		policies, err := s.enforcer.GetFilteredPolicy(1, permission.Resource, action)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			roles = append(roles, policy[0])
+		}
+	}
+	sort.Strings(roles)
+	return slices.Compact(roles), nil
+}
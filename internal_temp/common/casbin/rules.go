@@ -0,0 +1,151 @@
+package casbin
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ruleChange is a rule added or removed within a transaction, e.g. {"g2", ["user:3", "editor", "novel:12"], true}
+type ruleChange struct {
+	ptype string
+	rule  []string
+	add   bool
+}
+
+// filteredRules returns the rules of a policy type ("p", "g" or "g2") whose fields from fieldIndex match
+// fieldValues, an empty value matching any field. Within a transaction, the changes made so far are included
+func (s *CasbinService) filteredRules(ptype string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	var (
+		rules [][]string
+		err   error
+	)
+	if ptype == "p" {
+		rules, err = s.enforcer.GetFilteredNamedPolicy(ptype, fieldIndex, fieldValues...)
+	} else {
+		rules, err = s.enforcer.GetFilteredNamedGroupingPolicy(ptype, fieldIndex, fieldValues...)
+	}
+	if err != nil || s.changes == nil {
+		return rules, err
+	}
+
+	for _, change := range *s.changes {
+		if change.ptype != ptype || !ruleMatches(change.rule, fieldIndex, fieldValues) {
+			continue
+		}
+		index := slices.IndexFunc(rules, func(rule []string) bool { return slices.Equal(rule, change.rule) })
+		switch {
+		case change.add && index < 0:
+			rules = append(rules, change.rule)
+		case !change.add && index >= 0:
+			rules = slices.Delete(rules, index, index+1)
+		}
+	}
+	return rules, nil
+}
+
+// hasRule reports whether a rule exists, including the changes made so far within a transaction
+func (s *CasbinService) hasRule(ptype string, rule []string) (bool, error) {
+	rules, err := s.filteredRules(ptype, 0, rule...)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(rules, func(existing []string) bool { return slices.Equal(existing, rule) }), nil
+}
+
+// addRules writes the rules that don't exist yet with the transaction
+func (s *CasbinService) addRules(ptype string, rules ...[]string) error {
+	if s.tx == nil {
+		return fmt.Errorf("casbin rules can only be written within Transaction")
+	}
+
+	for _, rule := range rules {
+		exists, err := s.hasRule(ptype, rule)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		if err := s.adapter.AddPolicy(sectionOf(ptype), ptype, rule); err != nil {
+			return err
+		}
+		*s.changes = append(*s.changes, ruleChange{ptype: ptype, rule: slices.Clone(rule), add: true})
+	}
+	return nil
+}
+
+// removeRules deletes the rules that exist with the transaction
+func (s *CasbinService) removeRules(ptype string, rules ...[]string) error {
+	if s.tx == nil {
+		return fmt.Errorf("casbin rules can only be written within Transaction")
+	}
+
+	for _, rule := range rules {
+		exists, err := s.hasRule(ptype, rule)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		if err := s.adapter.RemovePolicy(sectionOf(ptype), ptype, rule); err != nil {
+			return err
+		}
+		*s.changes = append(*s.changes, ruleChange{ptype: ptype, rule: slices.Clone(rule), add: false})
+	}
+	return nil
+}
+
+// removeFilteredRules deletes the rules matched like filteredRules with the transaction
+func (s *CasbinService) removeFilteredRules(ptype string, fieldIndex int, fieldValues ...string) error {
+	rules, err := s.filteredRules(ptype, fieldIndex, fieldValues...)
+	if err != nil {
+		return err
+	}
+	return s.removeRules(ptype, rules...)
+}
+
+// applyChanges updates the enforcer with the changes of a committed transaction
+// Auto save is off, so this only changes the rules held in memory
+func (s *CasbinService) applyChanges(changes []ruleChange) error {
+	for _, change := range changes {
+		var err error
+		switch {
+		case change.ptype == "p" && change.add:
+			_, err = s.enforcer.AddNamedPolicy(change.ptype, change.rule)
+		case change.ptype == "p":
+			_, err = s.enforcer.RemoveNamedPolicy(change.ptype, change.rule)
+		case change.add:
+			_, err = s.enforcer.AddNamedGroupingPolicy(change.ptype, change.rule)
+		default:
+			_, err = s.enforcer.RemoveNamedGroupingPolicy(change.ptype, change.rule)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleMatches reports whether the fields of a rule from fieldIndex match fieldValues, like the enforcer's filters
+func ruleMatches(rule []string, fieldIndex int, fieldValues []string) bool {
+	for i, value := range fieldValues {
+		if value == "" {
+			continue
+		}
+		if fieldIndex+i >= len(rule) || rule[fieldIndex+i] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// sectionOf returns the model section of a policy type: "p" for policies, "g" for role assignments
+func sectionOf(ptype string) string {
+	if ptype == "p" {
+		return "p"
+	}
+	return "g"
+}
@@ -0,0 +1,210 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// watcherReconnectDelay is the wait before listening again after the connection is lost
+const watcherReconnectDelay = time.Second
+
+// policyNotifier announces policy changes to the other instances, nil until StartWatcher or StartNotifier is called
+var policyNotifier *PostgresNotifier
+
+// PostgresNotifier announces policy changes with Postgres NOTIFY, without listening for them
+// The payload is the ID of the sending instance, so an instance ignores its own notifications
+type PostgresNotifier struct {
+	db       *gorm.DB
+	channel  string
+	instance string
+}
+
+// NewPostgresNotifier sends notifications on the channel through db
+func NewPostgresNotifier(db *gorm.DB, channel string) *PostgresNotifier {
+	hostname, _ := os.Hostname()
+	return &PostgresNotifier{
+		db:       db,
+		channel:  channel,
+		instance: fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// Update tells the other instances that the policies changed
+func (n *PostgresNotifier) Update() error {
+	return n.db.Exec("SELECT pg_notify(?, ?)", n.channel, n.instance).Error
+}
+
+// PostgresWatcher is a Casbin watcher built on Postgres LISTEN/NOTIFY
+// Update notifies the channel and every other instance listening on it runs the update callback
+type PostgresWatcher struct {
+	*PostgresNotifier
+	dsn string
+
+	mu       sync.RWMutex
+	callback func(string)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPostgresWatcher listens on the channel with a dedicated connection to the database at dsn
+// Notifications are sent through db
+func NewPostgresWatcher(db *gorm.DB, dsn, channel string) (*PostgresWatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &PostgresWatcher{
+		PostgresNotifier: NewPostgresNotifier(db, channel),
+		dsn:              dsn,
+		cancel:           cancel,
+		done:             make(chan struct{}),
+	}
+
+	conn, err := w.listen(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to listen on %q: %w", channel, err)
+	}
+
+	go w.run(ctx, conn)
+	return w, nil
+}
+
+// SetUpdateCallback sets the function run when another instance changes the policies
+func (w *PostgresWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Close stops listening and closes the connection
+func (w *PostgresWatcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+// listen opens a connection and subscribes it to the channel
+func (w *PostgresWatcher) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, w.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{w.channel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return conn, nil
+}
+
+// run waits for notifications until Close is called, reconnecting when the connection is lost
+func (w *PostgresWatcher) run(ctx context.Context, conn *pgx.Conn) {
+	defer close(w.done)
+
+	for {
+		err := w.wait(ctx, conn)
+		conn.Close(context.Background())
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Warning: Casbin watcher lost its connection, reconnecting: %v", err)
+
+		for conn = nil; conn == nil; {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watcherReconnectDelay):
+			}
+
+			if conn, err = w.listen(ctx); err != nil {
+				log.Printf("Warning: Casbin watcher failed to reconnect: %v", err)
+			}
+		}
+
+		// Notifications sent while the connection was down are lost, so reload once listening again
+		w.notify("")
+	}
+}
+
+// wait runs the update callback for every notification of another instance
+func (w *PostgresWatcher) wait(ctx context.Context, conn *pgx.Conn) error {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		w.receive(notification.Payload)
+	}
+}
+
+// receive handles a notification, the ones this instance sent are skipped since its enforcer
+// already has the change
+func (w *PostgresWatcher) receive(payload string) {
+	if payload != w.instance {
+		w.notify(payload)
+	}
+}
+
+func (w *PostgresWatcher) notify(payload string) {
+	w.mu.RLock()
+	callback := w.callback
+	w.mu.RUnlock()
+
+	if callback != nil {
+		callback(payload)
+	}
+}
+
+// StartWatcher keeps the enforcer in sync with the other instances sharing the database
+// Policy changes committed through CasbinService are announced on the channel, and the policies are
+// reloaded when another instance announces a change
+func StartWatcher(db *gorm.DB, dsn, channel string) (*PostgresWatcher, error) {
+	if enforcer == nil {
+		return nil, fmt.Errorf("casbin enforcer is not initialized")
+	}
+
+	watcher, err := NewPostgresWatcher(db, dsn, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := watcher.SetUpdateCallback(reloadPolicies); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	policyNotifier = watcher.PostgresNotifier
+	return watcher, nil
+}
+
+// StartNotifier announces the policy changes committed through CasbinService on the channel,
+// for one-shot commands such as cmd/authz that don't need to hear about the changes of others
+func StartNotifier(db *gorm.DB, channel string) {
+	policyNotifier = NewPostgresNotifier(db, channel)
+}
+
+// reloadPolicies reloads the policies once the running transaction, if any, is applied to the enforcer
+// The enforcer is synced, so requests keep enforcing with the previous policies until the reload is done
+func reloadPolicies(string) {
+	transactionMu.Lock()
+	defer transactionMu.Unlock()
+
+	if err := enforcer.LoadPolicy(); err != nil {
+		log.Printf("Warning: Failed to reload Casbin policies: %v", err)
+	}
+}
+
+// notifyWatcher announces a policy change, it does nothing when no watcher or notifier is started
+func notifyWatcher() {
+	if policyNotifier == nil {
+		return
+	}
+	if err := policyNotifier.Update(); err != nil {
+		log.Printf("Warning: Failed to announce Casbin policy change: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package casbin
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWatcherReceive(t *testing.T) {
+	tests := []struct {
+		name     string
+		payloads []string
+		want     []string // payloads passed to the update callback
+	}{
+		{name: "change of another instance", payloads: []string{"other-1"}, want: []string{"other-1"}},
+		{name: "own change", payloads: []string{"self"}, want: nil},
+		{name: "own changes between others", payloads: []string{"self", "other-1", "self", "other-2"}, want: []string{"other-1", "other-2"}},
+		{name: "instance ID prefix", payloads: []string{"self-2", "sel"}, want: []string{"self-2", "sel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &PostgresWatcher{PostgresNotifier: &PostgresNotifier{instance: "self"}}
+			var got []string
+			if err := w.SetUpdateCallback(func(payload string) { got = append(got, payload) }); err != nil {
+				t.Fatalf("SetUpdateCallback() error = %v", err)
+			}
+
+			for _, payload := range tt.payloads {
+				w.receive(payload)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("callback payloads = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcherReceiveWithoutCallback(t *testing.T) {
+	w := &PostgresWatcher{PostgresNotifier: &PostgresNotifier{instance: "self"}}
+	// Notifications can arrive before the enforcer sets its callback
+	w.receive("other-1")
+}
+
+func TestNewPostgresNotifierInstances(t *testing.T) {
+	first := NewPostgresNotifier(nil, "casbin")
+	second := NewPostgresNotifier(nil, "casbin")
+
+	if first.instance == "" || first.instance == second.instance {
+		t.Errorf("instance IDs = %q and %q, want distinct IDs so each instance only skips its own notifications", first.instance, second.instance)
+	}
+}
@@ -43,6 +43,18 @@ const (
 	ErrCodeUserDeletionFailed     = "USER005"
 	ErrCodeUserInvalidCredentials = "USER006"
 	ErrCodeUserValidation         = "USER007"
+	ErrCodeUserEmailNotVerified   = "USER008"
+	ErrCodeUserSuspended          = "USER009"
+	ErrCodeUserBanned             = "USER010"
+	ErrCodeUserDeactivated        = "USER011"
+	ErrCodeUserWrongPassword      = "USER012"
+	ErrCodeUserEmailTaken         = "USER013"
+	ErrCodeUserAvatarInvalid      = "USER014"
+	ErrCodeUserHandleTaken        = "USER015"
+	ErrCodeUserHandleInvalid      = "USER016"
+	ErrCodeUserHandleReserved     = "USER017"
+	ErrCodeUserPasswordWeak       = "USER018"
+	ErrCodeUserPasswordBreached   = "USER019"
 
 	// Role domain errors (ROLE001-ROLE099)
 	ErrCodeRoleNotFound       = "ROLE001"
@@ -51,6 +63,14 @@ const (
 	ErrCodeRoleUpdateFailed   = "ROLE004"
 	ErrCodeRoleDeletionFailed = "ROLE005"
 	ErrCodeRoleValidation     = "ROLE006"
+	ErrCodeRolePermUnknown    = "ROLE007"
+	ErrCodeRolePermExists     = "ROLE008"
+	ErrCodeRolePermNotFound   = "ROLE009"
+	ErrCodeRoleParentCycle    = "ROLE010"
+	ErrCodeRoleParentExists   = "ROLE011"
+	ErrCodeRoleParentNotFound = "ROLE012"
+	ErrCodeRoleNameFixed      = "ROLE013"
+	ErrCodeRoleFileManaged    = "ROLE014"
 
 	// User-Role domain errors (USERROLE001-USERROLE099)
 	ErrCodeUserRoleNotFound       = "USERROLE001"
@@ -58,6 +78,7 @@ const (
 	ErrCodeUserRoleCreationFailed = "USERROLE003"
 	ErrCodeUserRoleUpdateFailed   = "USERROLE004"
 	ErrCodeUserRoleDeletionFailed = "USERROLE005"
+	ErrCodeUserRoleExpiryInvalid  = "USERROLE006"
 
 	// Auth domain errors (AUTH001-AUTH099)
 	ErrCodeAuthInvalidToken       = "AUTH001"
@@ -68,6 +89,35 @@ const (
 	ErrCodeAuthPasswordMismatch   = "AUTH006"
 	ErrCodeAuthRegistrationFailed = "AUTH007"
 	ErrCodeAuthLoginFailed        = "AUTH008"
+	ErrCodeAuthRefreshInvalid     = "AUTH009"
+	ErrCodeAuthRefreshReused      = "AUTH010"
+	ErrCodeAuthTokenRevoked       = "AUTH011"
+	ErrCodeAuthVerifyInvalid      = "AUTH012"
+	ErrCodeAuthEmailVerified      = "AUTH013"
+	ErrCodeAuthResetInvalid       = "AUTH014"
+	ErrCodeAuthMFAInvalidCode     = "AUTH015"
+	ErrCodeAuthMFANotEnrolled     = "AUTH016"
+	ErrCodeAuthMFAEnabled         = "AUTH017"
+	ErrCodeAuthAccountLocked      = "AUTH018"
+	ErrCodeAuthTooManyAttempts    = "AUTH019"
+	ErrCodeAuthOIDCProvider       = "AUTH020"
+	ErrCodeAuthOIDCState          = "AUTH021"
+	ErrCodeAuthOIDCFailed         = "AUTH022"
+	ErrCodeAuthOIDCConflict       = "AUTH023"
+	ErrCodeAuthOIDCNoEmail        = "AUTH024"
+	ErrCodeAuthAPIKeyInvalid      = "AUTH025"
+	ErrCodeAuthAPIKeyScope        = "AUTH026"
+	ErrCodeAuthSessionRequired    = "AUTH027"
+	ErrCodeAuthAccountInactive    = "AUTH028"
+	ErrCodeAuthTooManyResets      = "AUTH029"
+	ErrCodeAuthReauthRequired     = "AUTH030"
+
+	// Team domain errors (TEAM001-TEAM099)
+	ErrCodeTeamMemberNotFound = "TEAM001"
+	ErrCodeTeamAlreadyMember  = "TEAM002"
+	ErrCodeTeamRoleInvalid    = "TEAM003"
+	ErrCodeTeamNovelNotFound  = "TEAM004"
+	ErrCodeTeamValidation     = "TEAM005"
 
 	// Upload domain errors (UPLOAD001-UPLOAD099)
 	ErrCodeUploadInvalidFile  = "UPLOAD001"
@@ -108,12 +158,25 @@ var (
 	ErrUserUpdateFailed       = NewAppError(ErrCodeUserUpdateFailed, "Failed to update user")
 	ErrUserDeletionFailed     = NewAppError(ErrCodeUserDeletionFailed, "Failed to delete user")
 	ErrUserInvalidCredentials = NewAppError(ErrCodeUserInvalidCredentials, "Invalid username or password")
+	ErrUserEmailNotVerified   = NewAppError(ErrCodeUserEmailNotVerified, "Email address has not been verified")
+	ErrUserSuspended          = NewAppError(ErrCodeUserSuspended, "Account is suspended")
+	ErrUserBanned             = NewAppError(ErrCodeUserBanned, "Account is banned")
+	ErrUserDeactivated        = NewAppError(ErrCodeUserDeactivated, "Account is deactivated")
+	ErrUserWrongPassword      = NewAppError(ErrCodeUserWrongPassword, "Current password is incorrect")
+	ErrUserEmailTaken         = NewAppError(ErrCodeUserEmailTaken, "Email address is already in use")
+	ErrUserAvatarInvalid      = NewAppError(ErrCodeUserAvatarInvalid, "Avatar must be an image you uploaded")
+	ErrUserHandleTaken        = NewAppError(ErrCodeUserHandleTaken, "Username is already taken")
+	ErrUserHandleInvalid      = NewAppError(ErrCodeUserHandleInvalid, "Username must be 3 to 30 letters, digits or underscores")
+	ErrUserHandleReserved     = NewAppError(ErrCodeUserHandleReserved, "Username is reserved")
+	ErrUserPasswordWeak       = NewAppError(ErrCodeUserPasswordWeak, "Password does not meet the password policy")
+	ErrUserPasswordBreached   = NewAppError(ErrCodeUserPasswordBreached, "This password has appeared in a data breach, please choose another one")
 
 	//user - role related
 	ErrUserRoleAssignmentFailed = NewAppError(ErrCodeUserRoleNotFound, "Failed to assign role to user")
 	ErrUserRoleRemovalFailed    = NewAppError(ErrCodeUserRoleAlreadyExists, "Failed to remove role from user")
 	ErrUserDoesNotHaveRole      = NewAppError(ErrCodeUserRoleCreationFailed, "User does not have the specified role")
 	ErrUserAlreadyHasRole       = NewAppError(ErrCodeUserRoleUpdateFailed, "User already has the specified role")
+	ErrUserRoleExpiryInvalid    = NewAppError(ErrCodeUserRoleExpiryInvalid, "Role expiry must be in the future")
 
 	// role related
 	ErrRoleNotFound       = NewAppError(ErrCodeRoleNotFound, "Role not found")
@@ -121,6 +184,14 @@ var (
 	ErrRoleCreationFailed = NewAppError(ErrCodeRoleCreationFailed, "Failed to create role")
 	ErrRoleUpdateFailed   = NewAppError(ErrCodeRoleUpdateFailed, "Failed to update role")
 	ErrRoleDeletionFailed = NewAppError(ErrCodeRoleDeletionFailed, "Failed to delete role")
+	ErrRolePermUnknown    = NewAppError(ErrCodeRolePermUnknown, "Unknown permission")
+	ErrRolePermExists     = NewAppError(ErrCodeRolePermExists, "Role already has this permission")
+	ErrRolePermNotFound   = NewAppError(ErrCodeRolePermNotFound, "Role does not have this permission")
+	ErrRoleParentCycle    = NewAppError(ErrCodeRoleParentCycle, "Role inheritance would create a cycle")
+	ErrRoleParentExists   = NewAppError(ErrCodeRoleParentExists, "Role already inherits from this role")
+	ErrRoleParentNotFound = NewAppError(ErrCodeRoleParentNotFound, "Role does not inherit from this role")
+	ErrRoleNameFixed      = NewAppError(ErrCodeRoleNameFixed, "Team roles can't be renamed, they are assigned by name within teams")
+	ErrRoleFileManaged    = NewAppError(ErrCodeRoleFileManaged, "Role is managed by the policy file, change config/casbin/policy.csv and run cmd/authz apply instead")
 
 	// auth related
 	ErrAuthInvalidToken     = NewAppError(ErrCodeAuthInvalidToken, "Invalid authentication token")
@@ -130,6 +201,34 @@ var (
 	ErrAuthForbidden        = NewAppError(ErrCodeAuthForbidden, "Access forbidden")
 	ErrAuthPasswordMismatch = NewAppError(ErrCodeAuthPasswordMismatch, "Password and confirm password do not match")
 	ErrAuthLoginFailed      = NewAppError(ErrCodeAuthLoginFailed, "Login failed")
+	ErrAuthRefreshInvalid   = NewAppError(ErrCodeAuthRefreshInvalid, "Refresh token is invalid or expired")
+	ErrAuthRefreshReused    = NewAppError(ErrCodeAuthRefreshReused, "Refresh token has already been used, all sessions in this family were revoked")
+	ErrAuthTokenRevoked     = NewAppError(ErrCodeAuthTokenRevoked, "Authentication token has been revoked")
+	ErrAuthVerifyInvalid    = NewAppError(ErrCodeAuthVerifyInvalid, "Verification token is invalid or expired")
+	ErrAuthEmailVerified    = NewAppError(ErrCodeAuthEmailVerified, "Email address is already verified")
+	ErrAuthResetInvalid     = NewAppError(ErrCodeAuthResetInvalid, "Password reset token is invalid or expired")
+	ErrAuthMFAInvalidCode   = NewAppError(ErrCodeAuthMFAInvalidCode, "Invalid two-factor authentication code")
+	ErrAuthMFANotEnrolled   = NewAppError(ErrCodeAuthMFANotEnrolled, "Two-factor authentication is not set up")
+	ErrAuthMFAEnabled       = NewAppError(ErrCodeAuthMFAEnabled, "Two-factor authentication is already enabled")
+	ErrAuthAccountLocked    = NewAppError(ErrCodeAuthAccountLocked, "Account is temporarily locked after too many failed attempts")
+	ErrAuthTooManyAttempts  = NewAppError(ErrCodeAuthTooManyAttempts, "Too many failed login attempts, try again later")
+	ErrAuthOIDCProvider     = NewAppError(ErrCodeAuthOIDCProvider, "Unknown login provider")
+	ErrAuthOIDCState        = NewAppError(ErrCodeAuthOIDCState, "Login session is invalid or expired, please start again")
+	ErrAuthOIDCFailed       = NewAppError(ErrCodeAuthOIDCFailed, "Login with the external provider failed")
+	ErrAuthOIDCConflict     = NewAppError(ErrCodeAuthOIDCConflict, "An account with this email already exists, log in with your password to link it")
+	ErrAuthOIDCNoEmail      = NewAppError(ErrCodeAuthOIDCNoEmail, "The login provider did not share an email address")
+	ErrAuthAPIKeyInvalid    = NewAppError(ErrCodeAuthAPIKeyInvalid, "API key is invalid, expired or revoked")
+	ErrAuthAPIKeyScope      = NewAppError(ErrCodeAuthAPIKeyScope, "API key scope does not allow this action")
+	ErrAuthSessionRequired  = NewAppError(ErrCodeAuthSessionRequired, "This action requires a login session, API keys are not accepted")
+	ErrAuthAccountInactive  = NewAppError(ErrCodeAuthAccountInactive, "Account is no longer active")
+	ErrAuthTooManyResets    = NewAppError(ErrCodeAuthTooManyResets, "Too many password reset requests, try again later")
+	ErrAuthReauthRequired   = NewAppError(ErrCodeAuthReauthRequired, "Log in again or enter a two-factor authentication code to confirm this change")
+
+	// team related
+	ErrTeamMemberNotFound = NewAppError(ErrCodeTeamMemberNotFound, "Team member or invitation not found")
+	ErrTeamAlreadyMember  = NewAppError(ErrCodeTeamAlreadyMember, "User is already a member of this team or has a pending invitation")
+	ErrTeamRoleInvalid    = NewAppError(ErrCodeTeamRoleInvalid, "Role can't be assigned within a team")
+	ErrTeamNovelNotFound  = NewAppError(ErrCodeTeamNovelNotFound, "Novel not found")
 
 	// upload related
 	ErrUploadInvalidFile  = NewAppError(ErrCodeUploadInvalidFile, "Invalid file")
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/FeisalDy/nogo/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	mailer     Mailer
+	mailerOnce sync.Once
+)
+
+// InitMailer creates the mailer selected by MAIL_DRIVER
+func InitMailer(cfg config.MailConfig) (Mailer, error) {
+	var err error
+	mailerOnce.Do(func() {
+		switch cfg.Driver {
+		case "smtp":
+			mailer = NewSMTPMailer(cfg)
+		case "file":
+			mailer = NewFileMailer(cfg.From, cfg.FilePath)
+		case "log":
+			mailer = NewLogMailer(cfg.From)
+		default:
+			err = fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.Driver)
+		}
+	})
+
+	return mailer, err
+}
+
+func GetMailer() Mailer {
+	return mailer
+}
@@ -2,13 +2,20 @@ package middleware
 
 import (
 	"strings"
+	"sync"
 
+	authRepo "github.com/FeisalDy/nogo/internal/auth/repository"
+	authService "github.com/FeisalDy/nogo/internal/auth/service"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
+	userService "github.com/FeisalDy/nogo/internal/user/service"
 	"github.com/gin-gonic/gin"
 )
 
 // AuthMiddleware validates JWT token and adds user info to context
+// Scripts and bots may authenticate with a personal API key instead: "Authorization: ApiKey <key>"
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
@@ -19,14 +26,24 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Check if it's a Bearer token
+		// Check if it's a Bearer token or an API key
 		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 			utils.RespondWithAppError(c, errors.ErrAuthInvalidToken)
 			c.Abort()
 			return
 		}
 
+		if parts[0] == "ApiKey" {
+			if err := authenticateAPIKey(c, parts[1]); err != nil {
+				utils.HandleServiceError(c, err)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
 		tokenString := parts[1]
 
 		// Validate token
@@ -37,10 +54,25 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Refuse suspended, banned and deactivated users
+		if err := checkUserStatus(claims.UserID); err != nil {
+			utils.HandleServiceError(c, err)
+			c.Abort()
+			return
+		}
+
+		// Reject tokens revoked by logout
+		if isTokenRevoked(claims) {
+			utils.RespondWithAppError(c, errors.ErrAuthTokenRevoked)
+			c.Abort()
+			return
+		}
+
 		// Add user info to context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_username", claims.Username)
+		c.Set("token_claims", claims)
 
 		c.Next()
 	}
@@ -57,6 +89,12 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && parts[0] == "ApiKey" {
+			_ = authenticateAPIKey(c, parts[1])
+			c.Next()
+			return
+		}
+
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			c.Next()
 			return
@@ -64,7 +102,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 		tokenString := parts[1]
 		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || checkUserStatus(claims.UserID) != nil || isTokenRevoked(claims) {
 			c.Next()
 			return
 		}
@@ -73,11 +111,68 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_username", claims.Username)
+		c.Set("token_claims", claims)
 
 		c.Next()
 	}
 }
 
+// SessionOnly rejects requests authenticated with an API key
+// Use it after AuthMiddleware on routes that manage credentials, so a leaked key can't mint new ones
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIKey := GetAPIKeyScopes(c); isAPIKey {
+			utils.RespondWithAppError(c, errors.ErrAuthSessionRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+var (
+	apiKeyService     *authService.APIKeyService
+	apiKeyServiceOnce sync.Once
+)
+
+// authenticateAPIKey validates an API key and adds its user and scopes to the context
+func authenticateAPIKey(c *gin.Context, plainKey string) error {
+	apiKeyServiceOnce.Do(func() {
+		apiKeyService = authService.NewAPIKeyService(
+			authRepo.NewAPIKeyRepository(database.DB),
+			casbinService.NewCasbinService(database.DB),
+		)
+	})
+
+	key, err := apiKeyService.Authenticate(plainKey)
+	if err != nil {
+		return err
+	}
+	if err := checkUserStatus(key.UserID); err != nil {
+		return err
+	}
+
+	c.Set("user_id", key.UserID)
+	c.Set("api_key_id", key.ID)
+	c.Set("api_key_scopes", key.Scopes)
+	return nil
+}
+
+// checkUserStatus returns an error if the user's account is not active
+func checkUserStatus(userID uint) error {
+	store := userService.GetStatusStore()
+	if store == nil {
+		return nil
+	}
+	return store.Check(userID)
+}
+
+// isTokenRevoked checks the token against the revocation store
+func isTokenRevoked(claims *utils.JWTClaims) bool {
+	store := authService.GetRevocationStore()
+	return store != nil && store.IsRevoked(claims)
+}
+
 // GetUserID retrieves the user ID from the context
 func GetUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("user_id")
@@ -107,3 +202,24 @@ func GetUserUsername(c *gin.Context) (string, bool) {
 	usernameStr, ok := username.(string)
 	return usernameStr, ok
 }
+
+// GetTokenClaims retrieves the validated JWT claims from the context
+func GetTokenClaims(c *gin.Context) (*utils.JWTClaims, bool) {
+	claims, exists := c.Get("token_claims")
+	if !exists {
+		return nil, false
+	}
+	tokenClaims, ok := claims.(*utils.JWTClaims)
+	return tokenClaims, ok
+}
+
+// GetAPIKeyScopes retrieves the scopes of the API key used for the request
+// The second value is false when the request was not authenticated with an API key
+func GetAPIKeyScopes(c *gin.Context) ([]string, bool) {
+	scopes, exists := c.Get("api_key_scopes")
+	if !exists {
+		return nil, false
+	}
+	scopeList, ok := scopes.([]string)
+	return scopeList, ok
+}
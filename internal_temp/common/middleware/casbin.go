@@ -2,13 +2,24 @@ package middleware
 
 import (
 	"fmt"
+	"strconv"
 
 	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 )
 
+// explainOnDeny attaches an explanation of the decision to 403 responses
+var explainOnDeny bool
+
+// ConfigureExplainOnDeny enables the "explain" detail on 403 responses
+// It reveals the roles and policies behind a decision, so it should only be enabled in debug mode
+func ConfigureExplainOnDeny(enabled bool) {
+	explainOnDeny = enabled
+}
+
 // CasbinMiddleware is a middleware for Casbin authorization
 // It checks if the authenticated user has permission to access the resource
 func CasbinMiddleware(resource, action string) gin.HandlerFunc {
@@ -29,9 +40,9 @@ func CasbinMiddleware(resource, action string) gin.HandlerFunc {
 			return
 		}
 
-		// Check permission
+		// Check permission, within the request's domain if one was resolved
 		userSubject := fmt.Sprintf("user:%d", userID)
-		allowed, err := enforcer.Enforce(userSubject, resource, action)
+		allowed, err := enforceInRequestDomain(c, enforcer, userSubject, resource, action)
 		if err != nil {
 			utils.RespondWithAppError(c, errors.ErrInternalServer)
 			c.Abort()
@@ -39,7 +50,14 @@ func CasbinMiddleware(resource, action string) gin.HandlerFunc {
 		}
 
 		if !allowed {
-			utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+			respondDenied(c, enforcer, userSubject, resource, action)
+			c.Abort()
+			return
+		}
+
+		// API keys are additionally limited to their scopes
+		if !apiKeyScopeAllows(c, resource, action) {
+			utils.RespondWithAppError(c, errors.ErrAuthAPIKeyScope)
 			c.Abort()
 			return
 		}
@@ -94,9 +112,9 @@ func DynamicCasbinMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Check permission
+		// Check permission, within the request's domain if one was resolved
 		userSubject := fmt.Sprintf("user:%d", userID)
-		allowed, err := enforcer.Enforce(userSubject, resource, action)
+		allowed, err := enforceInRequestDomain(c, enforcer, userSubject, resource, action)
 		if err != nil {
 			utils.RespondWithAppError(c, errors.ErrInternalServer)
 			c.Abort()
@@ -104,7 +122,14 @@ func DynamicCasbinMiddleware() gin.HandlerFunc {
 		}
 
 		if !allowed {
-			utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+			respondDenied(c, enforcer, userSubject, resource, action)
+			c.Abort()
+			return
+		}
+
+		// API keys are additionally limited to their scopes
+		if !apiKeyScopeAllows(c, resource, action) {
+			utils.RespondWithAppError(c, errors.ErrAuthAPIKeyScope)
 			c.Abort()
 			return
 		}
@@ -231,6 +256,38 @@ func SetCasbinParams(resource, action string) gin.HandlerFunc {
 	}
 }
 
+// SetCasbinDomain sets the domain that Casbin middlewares enforce in
+func SetCasbinDomain(domain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("casbin_domain", domain)
+		c.Next()
+	}
+}
+
+// NovelDomain resolves the Casbin domain from the novel ID route param, e.g. "novel:12"
+// Casbin middlewares after it also apply the roles the user has on that novel's team
+func NovelDomain(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		novelID, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil {
+			utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+				"reason": err.Error(),
+			}))
+			c.Abort()
+			return
+		}
+
+		c.Set("casbin_domain", casbinService.FormatNovelDomain(uint(novelID)))
+		c.Next()
+	}
+}
+
+// GetCasbinDomain returns the Casbin domain resolved for the request, if any
+func GetCasbinDomain(c *gin.Context) (string, bool) {
+	domain := c.GetString("casbin_domain")
+	return domain, domain != ""
+}
+
 // Helper to get common action from HTTP method
 func GetActionFromMethod(method string) string {
 	switch method {
@@ -260,5 +317,55 @@ func PermissionChecker(c *gin.Context, resource, action string) (bool, error) {
 	}
 
 	userSubject := fmt.Sprintf("user:%d", userID)
+	return enforceInRequestDomain(c, enforcer, userSubject, resource, action)
+}
+
+// enforceInRequestDomain enforces within the request's domain when one was resolved,
+// so domain roles apply on top of global roles, and globally otherwise
+func enforceInRequestDomain(c *gin.Context, enforcer *casbin.SyncedEnforcer, userSubject, resource, action string) (bool, error) {
+	if domain, ok := GetCasbinDomain(c); ok {
+		return enforcer.Enforce(casbinService.DomainEnforceContext, userSubject, domain, resource, action)
+	}
 	return enforcer.Enforce(userSubject, resource, action)
 }
+
+// apiKeyScopeAllows checks the scopes of the API key used for the request
+// Requests authenticated with a JWT are not limited by scopes
+func apiKeyScopeAllows(c *gin.Context, resource, action string) bool {
+	scopes, isAPIKey := GetAPIKeyScopes(c)
+	if !isAPIKey {
+		return true
+	}
+
+	required := resource + ":" + action
+	for _, scope := range scopes {
+		if scope == required {
+			return true
+		}
+	}
+	return false
+}
+
+// respondDenied responds with ErrAuthUnauthorized, explaining why each of the actions was denied
+// when ConfigureExplainOnDeny is enabled
+func respondDenied(c *gin.Context, enforcer *casbin.SyncedEnforcer, userSubject, resource string, actions ...string) {
+	if !explainOnDeny {
+		utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+		return
+	}
+
+	domain, _ := GetCasbinDomain(c)
+	explanations := make([]*casbinService.Explanation, 0, len(actions))
+	for _, action := range actions {
+		explanation, err := casbinService.Explain(enforcer, userSubject, domain, resource, action)
+		if err != nil {
+			utils.RespondWithAppError(c, errors.ErrAuthUnauthorized)
+			return
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	utils.RespondWithAppError(c, errors.NewAppError(errors.ErrCodeAuthUnauthorized, errors.ErrAuthUnauthorized.Message).WithDetails(map[string]any{
+		"explain": explanations,
+	}))
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var initEnforcerOnce sync.Once
+
+// testEnforcer resets the package enforcer to no policies
+// The enforcer is created once per test binary. It keeps its rules in memory, so tests
+// can add them directly without a database behind it
+func testEnforcer(t *testing.T) {
+	t.Helper()
+
+	initEnforcerOnce.Do(func() {
+		db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		if _, err := casbinService.InitCasbin(db, "../../../config/casbin/model.conf"); err != nil {
+			t.Fatalf("init casbin: %v", err)
+		}
+	})
+
+	enforcer := casbinService.GetEnforcer()
+	if enforcer == nil {
+		t.Fatalf("casbin is not initialized")
+	}
+	// ClearPolicy keeps the role links of the previous test
+	enforcer.ClearPolicy()
+	if err := enforcer.BuildRoleLinks(); err != nil {
+		t.Fatalf("build role links: %v", err)
+	}
+}
+
+func TestOwnershipMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const userID = 1
+	// The novel the requests act on is owned by ownerID
+	const ownerID = 2
+
+	tests := []struct {
+		name        string
+		userID      uint     // 0 for an unauthenticated request
+		permissions []string // actions on "novels" granted to the user's role
+		domainRole  bool     // the role is only assigned in the novel's domain
+		apiScopes   []string // scopes of the API key, nil for a session
+		path        string
+		wantCode    string // error code, empty when access is granted
+	}{
+		{name: "owner with write:own", userID: ownerID, permissions: []string{"write:own"}, path: "/novels/7"},
+		{name: "other user with write:own", userID: userID, permissions: []string{"write:own"}, path: "/novels/7", wantCode: errors.ErrCodeAuthUnauthorized},
+		{name: "other user with write:any", userID: userID, permissions: []string{"write:any"}, path: "/novels/7"},
+		{name: "owner with write:any", userID: ownerID, permissions: []string{"write:any"}, path: "/novels/7"},
+		{name: "owner without permission", userID: ownerID, permissions: []string{"read:own"}, path: "/novels/7", wantCode: errors.ErrCodeAuthUnauthorized},
+		{name: "owner with write:own in the novel's domain", userID: ownerID, permissions: []string{"write:own"}, domainRole: true, path: "/novels/7"},
+		{name: "owner with write:own in another novel's domain", userID: ownerID, permissions: []string{"write:own"}, domainRole: true, path: "/novels/8", wantCode: errors.ErrCodeAuthUnauthorized},
+
+		{name: "API key of the owner scoped to write:own", userID: ownerID, permissions: []string{"write:own"}, apiScopes: []string{"novels:write:own"}, path: "/novels/7"},
+		{name: "API key of the owner scoped to write:any with write:own", userID: ownerID, permissions: []string{"write:own"}, apiScopes: []string{"novels:write:any"}, path: "/novels/7", wantCode: errors.ErrCodeAuthAPIKeyScope},
+		{name: "API key of the owner scoped to write:own with write:any", userID: ownerID, permissions: []string{"write:any"}, apiScopes: []string{"novels:write:own"}, path: "/novels/7"},
+		{name: "API key of another user scoped to write:own with write:any", userID: userID, permissions: []string{"write:any"}, apiScopes: []string{"novels:write:own"}, path: "/novels/7", wantCode: errors.ErrCodeAuthAPIKeyScope},
+		{name: "API key of another user scoped to write:any with write:any", userID: userID, permissions: []string{"write:any"}, apiScopes: []string{"novels:write:any"}, path: "/novels/7"},
+		{name: "API key without scopes", userID: ownerID, permissions: []string{"write:any"}, apiScopes: []string{}, path: "/novels/7", wantCode: errors.ErrCodeAuthAPIKeyScope},
+		{name: "API key scope doesn't widen the role", userID: userID, permissions: []string{"write:own"}, apiScopes: []string{"novels:write:any"}, path: "/novels/7", wantCode: errors.ErrCodeAuthUnauthorized},
+
+		{name: "unauthenticated", path: "/novels/7", wantCode: errors.ErrCodeAuthTokenMissing},
+		{name: "invalid ID", userID: ownerID, permissions: []string{"write:any"}, path: "/novels/abc", wantCode: errors.ErrCodeInvalidParam},
+		{name: "missing resource", userID: ownerID, permissions: []string{"write:any"}, path: "/novels/404", wantCode: errors.ErrCodeNotFound},
+	}
+
+	loader := func(id uint) ([]uint, error) {
+		if id == 404 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return []uint{ownerID}, nil
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testEnforcer(t)
+			enforcer := casbinService.GetEnforcer()
+			for _, action := range tt.permissions {
+				if _, err := enforcer.AddPolicy("writer", "novels", action); err != nil {
+					t.Fatalf("add policy: %v", err)
+				}
+			}
+			subject := casbinService.FormatUserSubject(tt.userID)
+			var err error
+			if tt.domainRole {
+				_, err = enforcer.AddNamedGroupingPolicy("g2", subject, "writer", casbinService.FormatNovelDomain(7))
+			} else {
+				_, err = enforcer.AddGroupingPolicy(subject, "writer")
+			}
+			if err != nil {
+				t.Fatalf("assign role: %v", err)
+			}
+
+			router := gin.New()
+			router.GET("/novels/:id",
+				func(c *gin.Context) {
+					if tt.userID != 0 {
+						c.Set("user_id", tt.userID)
+					}
+					if tt.apiScopes != nil {
+						c.Set("api_key_scopes", tt.apiScopes)
+					}
+				},
+				NovelDomain("id"),
+				OwnershipMiddleware("novels", "write", "id", loader),
+				func(c *gin.Context) {
+					owned, ok := GetOwnedResource(c)
+					if !ok || owned.ID != 7 || !owned.IsOwnedBy(ownerID) {
+						t.Errorf("GetOwnedResource() = %+v, %v", owned, ok)
+					}
+					c.Status(http.StatusNoContent)
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if tt.wantCode == "" {
+				if recorder.Code != http.StatusNoContent {
+					t.Fatalf("status = %d, want %d, body %s", recorder.Code, http.StatusNoContent, recorder.Body)
+				}
+				return
+			}
+			var response utils.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response %q: %v", recorder.Body, err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("error code = %s, want %s (%s)", response.Code, tt.wantCode, response.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs sent by clients, to fit the audit log column
+const maxRequestIDLength = 64
+
+// RequestID gives every request an ID, returned in the X-Request-ID response header
+// An ID sent by the client or a proxy is kept, so the request can be traced across services
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			// Left empty in the unlikely case the system's random source fails
+			requestID, _ = utils.GenerateOpaqueToken(16)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID retrieves the request ID from the context
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+// GetAuditActor returns the signed in user making the request, to record their policy changes
+// in the authz audit log
+func GetAuditActor(c *gin.Context) casbinService.AuditActor {
+	actor := casbinService.AuditActor{
+		Source:    casbinService.AuditSourceAPI,
+		RequestID: GetRequestID(c),
+	}
+	if userID, ok := GetUserID(c); ok {
+		actor.UserID = &userID
+	}
+	return actor
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/gin-gonic/gin"
+)
+
+// Routes registers the routes of a group along with the access each one requires,
+// so every route's permission is declared where it is registered and can be verified at startup
+//
+//	routes := middleware.DeclareRoutes(group)
+//	routes.Permission("roles", "read").GET("/:id", handler.GetRole)
+//
+// Authentication is not added by the declarations, routes other than public ones must be
+// registered on a group using AuthMiddleware, or pass it before their handler
+type Routes struct {
+	group *gin.RouterGroup
+}
+
+// DeclareRoutes starts declaring the routes of a group
+func DeclareRoutes(group *gin.RouterGroup) *Routes {
+	return &Routes{group: group}
+}
+
+// Public declares routes that don't require authentication
+func (r *Routes) Public() *RouteDeclaration {
+	return &RouteDeclaration{
+		group:      r.group,
+		permission: casbinService.RoutePermission{Access: casbinService.RouteAccessPublic},
+	}
+}
+
+// Authenticated declares routes open to every signed in user
+// They must only act on the caller's own account, e.g. their profile or sessions
+func (r *Routes) Authenticated() *RouteDeclaration {
+	return &RouteDeclaration{
+		group:      r.group,
+		permission: casbinService.RoutePermission{Access: casbinService.RouteAccessAuthenticated},
+	}
+}
+
+// Permission declares routes that require a permission, enforced with CasbinMiddleware
+func (r *Routes) Permission(resource, action string) *RouteDeclaration {
+	return &RouteDeclaration{
+		group: r.group,
+		permission: casbinService.RoutePermission{
+			Access:   casbinService.RouteAccessPermission,
+			Resource: resource,
+			Action:   action,
+		},
+		guard: CasbinMiddleware(resource, action),
+	}
+}
+
+// Ownership declares routes on a single resource that require "<action>:any", or "<action>:own"
+// when the user owns it, enforced with OwnershipMiddleware
+func (r *Routes) Ownership(resource, action, param string, loader casbinService.OwnerLoader) *RouteDeclaration {
+	return &RouteDeclaration{
+		group: r.group,
+		permission: casbinService.RoutePermission{
+			Access:   casbinService.RouteAccessOwnership,
+			Resource: resource,
+			Action:   action,
+		},
+		guard: OwnershipMiddleware(resource, action, param, loader),
+	}
+}
+
+// RouteDeclaration registers routes with the access it declares
+// The middleware enforcing the access runs right before the handler, after the other handlers given
+type RouteDeclaration struct {
+	group      *gin.RouterGroup
+	permission casbinService.RoutePermission
+	guard      gin.HandlerFunc // nil for public and authenticated routes
+}
+
+// GET registers a GET route
+func (d *RouteDeclaration) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	d.handle(http.MethodGet, relativePath, handlers)
+}
+
+// POST registers a POST route
+func (d *RouteDeclaration) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	d.handle(http.MethodPost, relativePath, handlers)
+}
+
+// PUT registers a PUT route
+func (d *RouteDeclaration) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	d.handle(http.MethodPut, relativePath, handlers)
+}
+
+// PATCH registers a PATCH route
+func (d *RouteDeclaration) PATCH(relativePath string, handlers ...gin.HandlerFunc) {
+	d.handle(http.MethodPatch, relativePath, handlers)
+}
+
+// DELETE registers a DELETE route
+func (d *RouteDeclaration) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	d.handle(http.MethodDelete, relativePath, handlers)
+}
+
+// handle registers the route, with the guard before the handler, and records its permission
+func (d *RouteDeclaration) handle(method, relativePath string, handlers []gin.HandlerFunc) {
+	if d.guard != nil && len(handlers) > 0 {
+		last := len(handlers) - 1
+		handlers = append(append(handlers[:last:last], d.guard), handlers[last])
+	}
+	d.group.Handle(method, relativePath, handlers...)
+
+	permission := d.permission
+	permission.Method = method
+	permission.Path = joinPaths(d.group.BasePath(), relativePath)
+	casbinService.RegisterRoutePermission(permission)
+}
+
+// joinPaths joins a group's base path and a relative path the way gin does, so the path
+// matches the one listed by gin.Engine.Routes
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
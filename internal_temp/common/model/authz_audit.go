@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// AuthzAudit records a change of the Casbin policies: who made it, and the rules it touched before and after
+// Rules are policy lines such as "p, admin, users, read" or "g, user:3, admin". Entries are never updated or deleted
+type AuthzAudit struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `gorm:"not null;index" json:"created_at"`
+	ActorID     *uint     `gorm:"index" json:"actor_id"` // nil for changes made by the system or the CLI
+	ActorSource string    `gorm:"not null;size:20" json:"actor_source"`
+	RequestID   string    `gorm:"size:64;index" json:"request_id,omitempty"`
+	Action      string    `gorm:"not null;size:50;index" json:"action"`
+	Target      string    `gorm:"not null;size:255;index" json:"target"`
+	Before      []string  `gorm:"type:text;not null;serializer:json" json:"before"`
+	After       []string  `gorm:"type:text;not null;serializer:json" json:"after"`
+}
+
+func (AuthzAudit) TableName() string {
+	return "authz_audit"
+}
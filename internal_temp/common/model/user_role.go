@@ -1,12 +1,21 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 // UserRole represents the many-to-many relationship between users and roles
+// An assignment with ExpiresAt is removed by the expiry sweeper once that time has passed
 type UserRole struct {
 	gorm.Model
-	UserID uint `gorm:"primaryKey;index:idx_user_role" json:"user_id"`
-	RoleID uint `gorm:"primaryKey;index:idx_user_role" json:"role_id"`
+	UserID    uint       `gorm:"primaryKey;index:idx_user_role" json:"user_id"`
+	RoleID    uint       `gorm:"primaryKey;index:idx_user_role" json:"role_id"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
+
+// IsExpired reports whether the assignment has expired at the given time
+func (ur *UserRole) IsExpired(now time.Time) bool {
+	return ur.ExpiresAt != nil && !now.Before(*ur.ExpiresAt)
 }
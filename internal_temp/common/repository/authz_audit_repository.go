@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/common/model"
+	"gorm.io/gorm"
+)
+
+// AuthzAuditRepository handles the authz audit log persistence
+// Entries can only be added and read, the table refuses updates and deletes
+type AuthzAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthzAuditRepository(db *gorm.DB) *AuthzAuditRepository {
+	return &AuthzAuditRepository{db: db}
+}
+
+func (r *AuthzAuditRepository) WithTx(tx *gorm.DB) *AuthzAuditRepository {
+	return &AuthzAuditRepository{db: tx}
+}
+
+func (r *AuthzAuditRepository) Create(entry *model.AuthzAudit) error {
+	return r.db.Create(entry).Error
+}
+
+// AuthzAuditFilter narrows down the audit log, zero values are not filtered on
+// Cursor is the ID of the last entry of the previous page
+type AuthzAuditFilter struct {
+	ActorID   uint
+	Action    string
+	Target    string
+	RequestID string
+	Since     *time.Time
+	Until     *time.Time
+	Cursor    uint
+	Limit     int
+}
+
+// List gets a page of audit entries matching the filter, newest first
+// hasMore reports whether older entries follow the page
+func (r *AuthzAuditRepository) List(filter AuthzAuditFilter) (entries []model.AuthzAudit, hasMore bool, err error) {
+	query := r.db.Model(&model.AuthzAudit{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.Cursor != 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+
+	// One more entry than the limit tells whether there is a next page
+	if err := query.Order("id DESC").Limit(filter.Limit + 1).Find(&entries).Error; err != nil {
+		return nil, false, err
+	}
+	if len(entries) > filter.Limit {
+		return entries[:filter.Limit], true, nil
+	}
+	return entries, false, nil
+}
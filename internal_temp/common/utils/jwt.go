@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/FeisalDy/nogo/config"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -12,59 +13,107 @@ type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
+	// SessionID is the login session the token belongs to; revoking the session revokes the token
+	SessionID uint `json:"sid,omitempty"`
+	// Purpose marks restricted tokens (e.g. "mfa_pending") that must not be accepted as access tokens
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// PurposeMFAPending is the purpose of the token handed out after the password
+// step of a login, until a valid second factor is presented
+const PurposeMFAPending = "mfa_pending"
+
 var (
-	JWTSecret       = []byte("your-secret-key-change-this-in-production")
-	TokenExpiration = 24 * time.Hour
+	TokenExpiration        = 15 * time.Minute
+	MFAPendingExpiration   = 5 * time.Minute
+	RefreshTokenExpiration = 30 * 24 * time.Hour
 )
 
-// GenerateToken generates a new JWT token for a user
-func GenerateToken(userID uint, email, username string) (string, error) {
+// ConfigureTokenExpiration applies the token lifetimes from the auth configuration
+func ConfigureTokenExpiration(cfg config.AuthConfig) {
+	if cfg.AccessTokenTTL > 0 {
+		TokenExpiration = cfg.AccessTokenTTL
+	}
+	if cfg.RefreshTokenTTL > 0 {
+		RefreshTokenExpiration = cfg.RefreshTokenTTL
+	}
+	if cfg.MFAPendingTTL > 0 {
+		MFAPendingExpiration = cfg.MFAPendingTTL
+	}
+}
+
+// GenerateToken generates a new JWT token for a user's login session
+func GenerateToken(userID uint, email, username string, sessionID uint) (string, error) {
+	// jti lets a single token be revoked server-side
+	tokenID, err := GenerateOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+
 	claims := JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
+		UserID:    userID,
+		Email:     email,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    jwtIssuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JWTSecret)
+	return signToken(claims)
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates a JWT access token and returns the claims
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (any, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return JWTSecret, nil
-	})
-
-	if err != nil {
+	claims := &JWTClaims{}
+	if err := parseToken(tokenString, claims); err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	if claims.Purpose != "" {
+		return nil, errors.New("token is not an access token")
 	}
-
-	return nil, errors.New("invalid token")
+	return claims, nil
 }
 
-// RefreshToken generates a new token with extended expiration
-func RefreshToken(oldTokenString string) (string, error) {
-	claims, err := ValidateToken(oldTokenString)
+// GenerateMFAPendingToken generates a short-lived token proving the password step of a login
+func GenerateMFAPendingToken(userID uint) (string, error) {
+	// jti lets the token be consumed once the second factor is verified
+	tokenID, err := GenerateOpaqueToken(16)
 	if err != nil {
 		return "", err
 	}
 
-	// Generate new token with the same user info
-	return GenerateToken(claims.UserID, claims.Email, claims.Username)
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:  userID,
+		Purpose: PurposeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    jwtIssuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(MFAPendingExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return signToken(claims)
+}
+
+// ValidateMFAPendingToken validates a token issued by GenerateMFAPendingToken
+func ValidateMFAPendingToken(tokenString string) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	if err := parseToken(tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != PurposeMFAPending {
+		return nil, errors.New("token is not an mfa pending token")
+	}
+	return claims, nil
 }
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a key used to sign and/or verify tokens
+// signKey is nil for verify-only keys that are kept around during a rotation
+type jwtKey struct {
+	id        string
+	signKey   any
+	verifyKey any
+}
+
+var (
+	jwtMethod  jwt.SigningMethod = jwt.SigningMethodHS256
+	jwtIssuer  string
+	activeKey  *jwtKey
+	verifyKeys = map[string]*jwtKey{}
+)
+
+// InitJWTKeys loads the JWT signing material from the auth configuration
+// HS256 uses JWT_SECRET. RS256 and EdDSA load every <kid>.pem (private) and
+// <kid>.pub.pem (verify-only) file from JWT_KEYS_DIR; the key named by
+// JWT_ACTIVE_KEY_ID signs new tokens and all loaded keys verify
+func InitJWTKeys(cfg config.AuthConfig) error {
+	jwtIssuer = cfg.JWTIssuer
+	keys := map[string]*jwtKey{}
+
+	switch strings.ToUpper(cfg.JWTAlgorithm) {
+	case "HS256":
+		if cfg.JWTSecret == "" {
+			return errors.New("JWT_SECRET is required when JWT_ALGORITHM is HS256")
+		}
+		jwtMethod = jwt.SigningMethodHS256
+		secret := []byte(cfg.JWTSecret)
+		keys[cfg.JWTActiveKeyID] = &jwtKey{id: cfg.JWTActiveKeyID, signKey: secret, verifyKey: secret}
+	case "RS256":
+		jwtMethod = jwt.SigningMethodRS256
+		if err := loadKeyDir(cfg.JWTKeysDir, keys, parseRSAKey); err != nil {
+			return err
+		}
+	case "EDDSA":
+		jwtMethod = jwt.SigningMethodEdDSA
+		if err := loadKeyDir(cfg.JWTKeysDir, keys, parseEdDSAKey); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.JWTAlgorithm)
+	}
+
+	active, ok := keys[cfg.JWTActiveKeyID]
+	if !ok || active.signKey == nil {
+		return fmt.Errorf("no private key found for JWT_ACTIVE_KEY_ID %q", cfg.JWTActiveKeyID)
+	}
+
+	activeKey = active
+	verifyKeys = keys
+	return nil
+}
+
+// loadKeyDir parses every PEM file in dir into keys, indexed by kid
+func loadKeyDir(dir string, keys map[string]*jwtKey, parse func(data []byte, private bool) (any, any, error)) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		private := !strings.HasSuffix(name, ".pub.pem")
+		kid := strings.TrimSuffix(strings.TrimSuffix(name, ".pem"), ".pub")
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT key %s: %w", name, err)
+		}
+
+		signKey, verifyKey, err := parse(data, private)
+		if err != nil {
+			return fmt.Errorf("failed to parse JWT key %s: %w", name, err)
+		}
+
+		// A private key also provides the public half, so it wins over a .pub.pem of the same kid
+		if existing, ok := keys[kid]; ok && existing.signKey != nil {
+			continue
+		}
+		keys[kid] = &jwtKey{id: kid, signKey: signKey, verifyKey: verifyKey}
+	}
+
+	return nil
+}
+
+func parseRSAKey(data []byte, private bool) (any, any, error) {
+	if !private {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		return nil, publicKey, err
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, &privateKey.PublicKey, nil
+}
+
+func parseEdDSAKey(data []byte, private bool) (any, any, error) {
+	if !private {
+		publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+		return nil, publicKey, err
+	}
+	privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	edKey, ok := privateKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("not an Ed25519 private key")
+	}
+	return edKey, edKey.Public(), nil
+}
+
+// signToken signs claims with the active key and sets its kid header
+func signToken(claims jwt.Claims) (string, error) {
+	if activeKey == nil {
+		return "", errors.New("JWT keys are not initialized")
+	}
+
+	token := jwt.NewWithClaims(jwtMethod, claims)
+	if activeKey.id != "" {
+		token.Header["kid"] = activeKey.id
+	}
+	return token.SignedString(activeKey.signKey)
+}
+
+// parseToken verifies a token against the key named by its kid header
+func parseToken(tokenString string, claims jwt.Claims) error {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwtMethod.Alg()})}
+	if jwtIssuer != "" {
+		options = append(options, jwt.WithIssuer(jwtIssuer))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := verifyKeys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.verifyKey, nil
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+// JWK is a single public key in a JSON Web Key Set (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GetJWKS returns the public verification keys
+// HMAC secrets are never published, so the set is empty with HS256
+func GetJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	kids := make([]string, 0, len(verifyKeys))
+	for kid := range verifyKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		switch key := verifyKeys[kid].verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwtMethod.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwtMethod.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey writes the private key as <kid>.pem, or only its public half as <kid>.pub.pem
+func writeKey(t *testing.T, dir, kid string, key crypto.Signer, private bool) {
+	t.Helper()
+
+	name, block := kid+".pub.pem", "PUBLIC KEY"
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if private {
+		name, block = kid+".pem", "PRIVATE KEY"
+		der, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: block, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		kty       string
+		newKey    func() (crypto.Signer, error)
+	}{
+		{
+			name:      "RS256",
+			algorithm: "RS256",
+			kty:       "RSA",
+			newKey:    func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+		},
+		{
+			name:      "EdDSA",
+			algorithm: "EdDSA",
+			kty:       "OKP",
+			newKey: func() (crypto.Signer, error) {
+				_, key, err := ed25519.GenerateKey(rand.Reader)
+				return key, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config.AuthConfig{JWTAlgorithm: tt.algorithm, JWTKeysDir: dir, JWTIssuer: "nogo-test"}
+
+			oldKey, err := tt.newKey()
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+			newKey, err := tt.newKey()
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+
+			// 1. Sign with the old key
+			writeKey(t, dir, "old", oldKey, true)
+			cfg.JWTActiveKeyID = "old"
+			if err := InitJWTKeys(cfg); err != nil {
+				t.Fatalf("InitJWTKeys(old) error = %v", err)
+			}
+			oldToken, err := GenerateToken(1, "a@example.com", "a", 1)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			// 2. Rotate: the new key signs, the old one is kept to verify tokens issued before
+			os.Remove(filepath.Join(dir, "old.pem"))
+			writeKey(t, dir, "old", oldKey, false)
+			writeKey(t, dir, "new", newKey, true)
+			cfg.JWTActiveKeyID = "new"
+			if err := InitJWTKeys(cfg); err != nil {
+				t.Fatalf("InitJWTKeys(new) error = %v", err)
+			}
+			newToken, err := GenerateToken(1, "a@example.com", "a", 1)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			if kid := tokenKid(t, newToken); kid != "new" {
+				t.Errorf("new token kid = %q, want %q", kid, "new")
+			}
+			for _, token := range []string{oldToken, newToken} {
+				if _, err := ValidateToken(token); err != nil {
+					t.Errorf("ValidateToken(%s) during rotation error = %v", tokenKid(t, token), err)
+				}
+			}
+
+			jwks := GetJWKS()
+			if len(jwks.Keys) != 2 {
+				t.Fatalf("JWKS keys = %d, want 2", len(jwks.Keys))
+			}
+			for i, kid := range []string{"new", "old"} {
+				key := jwks.Keys[i]
+				if key.Kid != kid || key.Kty != tt.kty || key.Alg != jwt.GetSigningMethod(tt.algorithm).Alg() || key.Use != "sig" {
+					t.Errorf("JWKS key %d = %+v, want kid %q kty %q", i, key, kid, tt.kty)
+				}
+			}
+
+			// 3. Retire the old key: its tokens stop verifying
+			os.Remove(filepath.Join(dir, "old.pub.pem"))
+			if err := InitJWTKeys(cfg); err != nil {
+				t.Fatalf("InitJWTKeys(retired) error = %v", err)
+			}
+			if _, err := ValidateToken(oldToken); err == nil {
+				t.Error("ValidateToken() accepted a token signed with a retired key")
+			}
+			if _, err := ValidateToken(newToken); err != nil {
+				t.Errorf("ValidateToken(new) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestInitJWTKeys(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	writeKey(t, dir, "public-only", key, false)
+
+	tests := []struct {
+		name     string
+		cfg      config.AuthConfig
+		wantErr  bool
+		wantJWKS int
+	}{
+		{name: "HS256 publishes no keys", cfg: config.AuthConfig{JWTAlgorithm: "HS256", JWTSecret: "secret"}, wantJWKS: 0},
+		{name: "HS256 without secret", cfg: config.AuthConfig{JWTAlgorithm: "HS256"}, wantErr: true},
+		{name: "active key without private key", cfg: config.AuthConfig{JWTAlgorithm: "RS256", JWTKeysDir: dir, JWTActiveKeyID: "public-only"}, wantErr: true},
+		{name: "unknown active key", cfg: config.AuthConfig{JWTAlgorithm: "RS256", JWTKeysDir: dir, JWTActiveKeyID: "missing"}, wantErr: true},
+		{name: "unsupported algorithm", cfg: config.AuthConfig{JWTAlgorithm: "none"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := InitJWTKeys(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitJWTKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := len(GetJWKS().Keys); got != tt.wantJWKS {
+				t.Errorf("JWKS keys = %d, want %d", got, tt.wantJWKS)
+			}
+		})
+	}
+}
@@ -1,12 +1,76 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FeisalDy/nogo/config"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Password hashing algorithms
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// passwordHashing holds the settings new hashes are created with
+// Set once at startup by ConfigurePasswordHashing
+var passwordHashing = config.PasswordConfig{
+	Algorithm:         PasswordAlgorithmBcrypt,
+	BcryptCost:        bcrypt.DefaultCost,
+	Argon2Memory:      64 * 1024,
+	Argon2Iterations:  3,
+	Argon2Parallelism: 2,
+}
+
+// argon2Params are the parameters encoded in an argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// ConfigurePasswordHashing applies the hashing algorithm and cost from the password configuration
+func ConfigurePasswordHashing(cfg config.PasswordConfig) error {
+	switch cfg.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if cfg.Argon2Memory == 0 || cfg.Argon2Iterations == 0 || cfg.Argon2Parallelism == 0 {
+			return errors.New("argon2id memory, iterations and parallelism must be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported password hash algorithm %q", cfg.Algorithm)
+	}
+
+	passwordHashing = cfg
+	return nil
+}
+
+// HashPassword hashes a password with the configured algorithm and cost
 func HashPassword(password string) (string, error) {
-	// Generate a hash from the password with default cost (10)
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if passwordHashing.Algorithm == PasswordAlgorithmArgon2id {
+		return hashArgon2id(password, argon2Params{
+			memory:      passwordHashing.Argon2Memory,
+			iterations:  passwordHashing.Argon2Iterations,
+			parallelism: passwordHashing.Argon2Parallelism,
+		})
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashing.BcryptCost)
 	if err != nil {
 		return "", err
 	}
@@ -14,8 +78,91 @@ func HashPassword(password string) (string, error) {
 }
 
 // ComparePassword compares a plain text password with a hashed password
+// Both bcrypt and argon2id hashes are accepted, whatever the configured algorithm
 // Returns true if they match, false otherwise
 func ComparePassword(hashedPassword, password string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
 }
+
+// PasswordNeedsRehash reports whether a stored hash uses another algorithm or a
+// lower cost than the configured one, so it should be replaced after a successful login
+func PasswordNeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		if passwordHashing.Algorithm != PasswordAlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return true
+		}
+		return params.memory < passwordHashing.Argon2Memory ||
+			params.iterations < passwordHashing.Argon2Iterations ||
+			params.parallelism < passwordHashing.Argon2Parallelism
+	}
+
+	if passwordHashing.Algorithm != PasswordAlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return true
+	}
+	return cost < passwordHashing.BcryptCost
+}
+
+// hashArgon2id hashes a password in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func hashArgon2id(password string, params argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/FeisalDy/nogo/config"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+)
+
+// bcryptMaxLength is the number of bytes bcrypt looks at; longer passwords are rejected
+const bcryptMaxLength = 72
+
+var (
+	passwordPolicy = config.PasswordConfig{MinLength: 8}
+	// breachedPasswords holds lowercased known breached passwords
+	breachedPasswords = map[string]struct{}{}
+)
+
+// InitPasswordPolicy applies the password policy and loads the breached password list
+// A missing list file is not an error, so the check can be left out in development
+func InitPasswordPolicy(cfg config.PasswordConfig) error {
+	passwordPolicy = cfg
+
+	if cfg.BreachedListPath == "" {
+		return nil
+	}
+
+	file, err := os.Open(cfg.BreachedListPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	breachedPasswords = breached
+	return nil
+}
+
+// ValidatePassword checks a new password against the password policy
+// Returns ErrUserPasswordWeak with the unmet requirements, or ErrUserPasswordBreached
+func ValidatePassword(password string) error {
+	var unmet []string
+
+	if len([]rune(password)) < passwordPolicy.MinLength {
+		unmet = append(unmet, fmt.Sprintf("at least %d characters", passwordPolicy.MinLength))
+	}
+	if passwordHashing.Algorithm == PasswordAlgorithmBcrypt && len(password) > bcryptMaxLength {
+		unmet = append(unmet, fmt.Sprintf("at most %d bytes", bcryptMaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if passwordPolicy.RequireUpper && !hasUpper {
+		unmet = append(unmet, "an uppercase letter")
+	}
+	if passwordPolicy.RequireLower && !hasLower {
+		unmet = append(unmet, "a lowercase letter")
+	}
+	if passwordPolicy.RequireDigit && !hasDigit {
+		unmet = append(unmet, "a digit")
+	}
+	if passwordPolicy.RequireSymbol && !hasSymbol {
+		unmet = append(unmet, "a symbol")
+	}
+
+	if len(unmet) > 0 {
+		return errors.NewAppError(errors.ErrCodeUserPasswordWeak, errors.ErrUserPasswordWeak.Message).
+			WithDetails(map[string]interface{}{"requirements": unmet})
+	}
+
+	if _, breached := breachedPasswords[strings.ToLower(password)]; breached {
+		return errors.ErrUserPasswordBreached
+	}
+	return nil
+}
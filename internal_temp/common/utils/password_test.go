@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FeisalDy/nogo/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Low costs keep the tests fast, the checks only compare them
+var (
+	testBcrypt       = config.PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+	testBcryptHigher = config.PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}
+	testArgon2       = config.PasswordConfig{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+)
+
+// withPasswordHashing applies cfg for the rest of the test
+func withPasswordHashing(t *testing.T, cfg config.PasswordConfig) {
+	t.Helper()
+	previous := passwordHashing
+	t.Cleanup(func() { passwordHashing = previous })
+
+	if err := ConfigurePasswordHashing(cfg); err != nil {
+		t.Fatalf("ConfigurePasswordHashing() error = %v", err)
+	}
+}
+
+// hashWith hashes password with cfg, leaving the configured hashing unchanged
+func hashWith(t *testing.T, cfg config.PasswordConfig, password string) string {
+	t.Helper()
+	previous := passwordHashing
+	defer func() { passwordHashing = previous }()
+
+	if err := ConfigurePasswordHashing(cfg); err != nil {
+		t.Fatalf("ConfigurePasswordHashing() error = %v", err)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	return hash
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.PasswordConfig
+		other      config.PasswordConfig // configured after hashing, the hash must still verify
+		wantPrefix string
+	}{
+		{name: "bcrypt", cfg: testBcrypt, other: testArgon2, wantPrefix: "$2a$"},
+		{name: "argon2id", cfg: testArgon2, other: testBcrypt, wantPrefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPasswordHashing(t, tt.cfg)
+
+			hash, err := HashPassword("correct horse")
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("HashPassword() = %q, want prefix %q", hash, tt.wantPrefix)
+			}
+
+			again, err := HashPassword("correct horse")
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if hash == again {
+				t.Errorf("HashPassword() returned the same hash twice, the salt isn't random")
+			}
+
+			if !ComparePassword(hash, "correct horse") {
+				t.Errorf("ComparePassword() = false for the right password")
+			}
+			if ComparePassword(hash, "correct horse!") {
+				t.Errorf("ComparePassword() = true for a wrong password")
+			}
+
+			withPasswordHashing(t, tt.other)
+			if !ComparePassword(hash, "correct horse") {
+				t.Errorf("ComparePassword() = false after switching the algorithm")
+			}
+		})
+	}
+}
+
+func TestComparePasswordMalformed(t *testing.T) {
+	valid := hashWith(t, testArgon2, "correct horse")
+	parts := strings.Split(valid, "$")
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "empty", hash: ""},
+		{name: "not a hash", hash: "correct horse"},
+		{name: "missing key", hash: strings.Join(parts[:5], "$")},
+		{name: "other argon2 version", hash: strings.Replace(valid, "v=19", "v=16", 1)},
+		{name: "invalid parameters", hash: strings.Replace(valid, "m=1024,t=1,p=1", "m=x,t=1,p=1", 1)},
+		{name: "invalid salt", hash: strings.Replace(valid, parts[4], "!!!", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ComparePassword(tt.hash, "correct horse") {
+				t.Errorf("ComparePassword(%q) = true, want false", tt.hash)
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	argon2With := func(memory, iterations uint32, parallelism uint8) config.PasswordConfig {
+		return config.PasswordConfig{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: memory, Argon2Iterations: iterations, Argon2Parallelism: parallelism}
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.PasswordConfig // configured hashing
+		hash string
+		want bool
+	}{
+		{name: "bcrypt at the configured cost", cfg: testBcrypt, hash: hashWith(t, testBcrypt, "pw")},
+		{name: "bcrypt above the configured cost", cfg: testBcrypt, hash: hashWith(t, testBcryptHigher, "pw")},
+		{name: "bcrypt below the configured cost", cfg: testBcryptHigher, hash: hashWith(t, testBcrypt, "pw"), want: true},
+		{name: "bcrypt when argon2id is configured", cfg: testArgon2, hash: hashWith(t, testBcrypt, "pw"), want: true},
+		{name: "argon2id when bcrypt is configured", cfg: testBcrypt, hash: hashWith(t, testArgon2, "pw"), want: true},
+		{name: "argon2id with the configured parameters", cfg: testArgon2, hash: hashWith(t, testArgon2, "pw")},
+		{name: "argon2id with stronger parameters", cfg: testArgon2, hash: hashWith(t, argon2With(2048, 2, 2), "pw")},
+		{name: "argon2id with less memory", cfg: argon2With(2048, 1, 1), hash: hashWith(t, testArgon2, "pw"), want: true},
+		{name: "argon2id with fewer iterations", cfg: argon2With(1024, 2, 1), hash: hashWith(t, testArgon2, "pw"), want: true},
+		{name: "argon2id with less parallelism", cfg: argon2With(1024, 1, 2), hash: hashWith(t, testArgon2, "pw"), want: true},
+		{name: "malformed argon2id", cfg: testArgon2, hash: "$argon2id$v=19$garbage", want: true},
+		{name: "malformed bcrypt", cfg: testBcrypt, hash: "not a hash", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPasswordHashing(t, tt.cfg)
+
+			if got := PasswordNeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("PasswordNeedsRehash(%q) = %v, want %v", tt.hash, got, tt.want)
+			}
+		})
+	}
+}
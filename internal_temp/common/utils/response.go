@@ -90,21 +90,61 @@ func GetStatusCodeFromErrorCode(code string) int {
 	// User errors
 	case errors.ErrCodeUserNotFound:
 		return http.StatusNotFound
-	case errors.ErrCodeUserAlreadyExists:
+	case errors.ErrCodeUserAlreadyExists, errors.ErrCodeUserEmailTaken, errors.ErrCodeUserHandleTaken:
 		return http.StatusConflict
 	case errors.ErrCodeUserCreationFailed, errors.ErrCodeUserUpdateFailed, errors.ErrCodeUserDeletionFailed:
 		return http.StatusInternalServerError
 	case errors.ErrCodeUserInvalidCredentials:
 		return http.StatusUnauthorized
-	case errors.ErrCodeUserValidation:
+	case errors.ErrCodeUserValidation, errors.ErrCodeUserWrongPassword, errors.ErrCodeUserAvatarInvalid:
+		return http.StatusBadRequest
+	case errors.ErrCodeUserHandleInvalid, errors.ErrCodeUserHandleReserved, errors.ErrCodeUserPasswordWeak, errors.ErrCodeUserPasswordBreached:
+		return http.StatusBadRequest
+	case errors.ErrCodeUserEmailNotVerified, errors.ErrCodeUserSuspended, errors.ErrCodeUserBanned, errors.ErrCodeUserDeactivated:
+		return http.StatusForbidden
+
+	// Role errors
+	case errors.ErrCodeRoleNotFound, errors.ErrCodeRolePermNotFound, errors.ErrCodeRoleParentNotFound:
+		return http.StatusNotFound
+	case errors.ErrCodeRoleAlreadyExists, errors.ErrCodeRolePermExists, errors.ErrCodeRoleParentCycle, errors.ErrCodeRoleParentExists, errors.ErrCodeRoleNameFixed, errors.ErrCodeRoleFileManaged:
+		return http.StatusConflict
+	case errors.ErrCodeRoleValidation, errors.ErrCodeRolePermUnknown:
+		return http.StatusBadRequest
+
+	// User-Role errors
+	case errors.ErrCodeUserRoleExpiryInvalid:
 		return http.StatusBadRequest
 
 	// Auth errors
 	case errors.ErrCodeAuthInvalidToken, errors.ErrCodeAuthTokenExpired, errors.ErrCodeAuthTokenMissing, errors.ErrCodeAuthUnauthorized, errors.ErrCodeAuthLoginFailed:
 		return http.StatusUnauthorized
-	case errors.ErrCodeAuthForbidden:
+	case errors.ErrCodeAuthRefreshInvalid, errors.ErrCodeAuthRefreshReused, errors.ErrCodeAuthTokenRevoked:
+		return http.StatusUnauthorized
+	case errors.ErrCodeAuthMFAInvalidCode, errors.ErrCodeAuthOIDCFailed, errors.ErrCodeAuthAPIKeyInvalid:
+		return http.StatusUnauthorized
+	case errors.ErrCodeAuthOIDCProvider:
+		return http.StatusNotFound
+	case errors.ErrCodeAuthForbidden, errors.ErrCodeAuthAPIKeyScope, errors.ErrCodeAuthSessionRequired, errors.ErrCodeAuthAccountInactive:
+		return http.StatusForbidden
+	case errors.ErrCodeAuthReauthRequired:
 		return http.StatusForbidden
-	case errors.ErrCodeAuthPasswordMismatch, errors.ErrCodeAuthRegistrationFailed:
+	case errors.ErrCodeAuthAccountLocked:
+		return http.StatusLocked
+	case errors.ErrCodeAuthTooManyAttempts, errors.ErrCodeAuthTooManyResets:
+		return http.StatusTooManyRequests
+	case errors.ErrCodeAuthPasswordMismatch, errors.ErrCodeAuthRegistrationFailed, errors.ErrCodeAuthVerifyInvalid, errors.ErrCodeAuthResetInvalid, errors.ErrCodeAuthMFANotEnrolled:
+		return http.StatusBadRequest
+	case errors.ErrCodeAuthOIDCState, errors.ErrCodeAuthOIDCNoEmail:
+		return http.StatusBadRequest
+	case errors.ErrCodeAuthEmailVerified, errors.ErrCodeAuthMFAEnabled, errors.ErrCodeAuthOIDCConflict:
+		return http.StatusConflict
+
+	// Team errors
+	case errors.ErrCodeTeamMemberNotFound, errors.ErrCodeTeamNovelNotFound:
+		return http.StatusNotFound
+	case errors.ErrCodeTeamAlreadyMember:
+		return http.StatusConflict
+	case errors.ErrCodeTeamRoleInvalid, errors.ErrCodeTeamValidation:
 		return http.StatusBadRequest
 
 	// Upload errors
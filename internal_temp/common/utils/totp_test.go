@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 test secret "12345678901234567890", codes are the last 6 digits of its SHA1 vectors
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "RFC vector at 59", secret: secret, code: "287082", at: 59, wantStep: 1, wantOK: true},
+		{name: "RFC vector at 1111111109", secret: secret, code: "081804", at: 1111111109, wantStep: 37037036, wantOK: true},
+		{name: "RFC vector at 1234567890", secret: secret, code: "005924", at: 1234567890, wantStep: 41152263, wantOK: true},
+		{name: "lowercase secret and spaces", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: "287 082", at: 59, wantStep: 1, wantOK: true},
+		{name: "previous step within skew", secret: secret, code: "287082", at: 59 + 30, wantStep: 1, wantOK: true},
+		{name: "next step within skew", secret: secret, code: "287082", at: 59 - 30, wantStep: 1, wantOK: true},
+		{name: "outside skew", secret: secret, code: "287082", at: 59 + 60},
+		{name: "wrong code", secret: secret, code: "287083", at: 59},
+		{name: "too short", secret: secret, code: "28708", at: 59},
+		{name: "invalid secret", secret: "not base32!", code: "287082", at: 59},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.at, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCode() error = %v", err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Fatalf("GenerateRecoveryCode() = %q, want xxxxx-xxxxx", code)
+	}
+
+	want := code[:5] + code[6:]
+	for _, typed := range []string{code, want, " " + code + " ", code[:5] + " " + code[6:]} {
+		if got := NormalizeRecoveryCode(typed); got != want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", typed, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package databasetest opens in-memory databases for tests
+package databasetest
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open returns an empty in-memory SQLite database with the tables of the given models
+// The database is closed when the test ends
+func Open(t testing.TB, models ...any) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("databasetest: failed to open database: %v", err)
+	}
+
+	// Every connection to an in-memory database gets a database of its own, so they share one
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("databasetest: failed to get connection pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("databasetest: failed to migrate: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken stores hashed opaque refresh tokens
+// Tokens issued from the same login share a FamilyID so that reuse of a
+// rotated token can revoke the whole family
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FamilyID  string    `gorm:"not null;size:64;index"`
+	TokenHash string    `gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time `gorm:"index"`
+}
+
+// Migration008CreateRefreshTokens creates the refresh_tokens table
+func Migration008CreateRefreshTokens() Migration {
+	return Migration{
+		ID:          "008_create_refresh_tokens",
+		Description: "Create refresh_tokens table for refresh token rotation",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&RefreshToken{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&RefreshToken{})
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevokedToken records revoked access tokens
+// A row either revokes a single token (JTI set) or every token of a user
+// issued before IssuedBefore ("logout everywhere")
+// Rows can be pruned once ExpiresAt has passed
+type RevokedToken struct {
+	gorm.Model
+	JTI          *string `gorm:"column:jti;size:64;uniqueIndex"`
+	UserID       uint    `gorm:"not null;index"`
+	User         *User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	IssuedBefore *time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
+
+// Migration009CreateRevokedTokens creates the revoked_tokens table
+func Migration009CreateRevokedTokens() Migration {
+	return Migration{
+		ID:          "009_create_revoked_tokens",
+		Description: "Create revoked_tokens table for server-side JWT revocation",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&RevokedToken{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&RevokedToken{})
+		},
+	}
+}
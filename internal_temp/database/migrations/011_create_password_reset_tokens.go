@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken stores hashed single-use password reset tokens
+type PasswordResetToken struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	TokenHash string    `gorm:"not null;size:64;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+}
+
+// Migration011CreatePasswordResetTokens creates the password_reset_tokens table
+func Migration011CreatePasswordResetTokens() Migration {
+	return Migration{
+		ID:          "011_create_password_reset_tokens",
+		Description: "Create password_reset_tokens table for the forgot/reset password flow",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&PasswordResetToken{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&PasswordResetToken{})
+		},
+	}
+}
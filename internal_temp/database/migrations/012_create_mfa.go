@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MFASecret stores the TOTP secret of a user
+// The secret is pending until EnabledAt is set by confirming a first code
+// LastUsedStep is the last accepted TOTP time step, used to reject replayed codes
+type MFASecret struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;uniqueIndex"`
+	User         *User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Secret       string `gorm:"not null;size:64"`
+	EnabledAt    *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+}
+
+// MFARecoveryCode stores hashed single-use recovery codes
+type MFARecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	User     *User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CodeHash string `gorm:"not null;size:64;index"`
+	UsedAt   *time.Time
+}
+
+// Migration012CreateMFA creates the mfa_secrets and mfa_recovery_codes tables
+func Migration012CreateMFA() Migration {
+	return Migration{
+		ID:          "012_create_mfa",
+		Description: "Create mfa_secrets and mfa_recovery_codes tables for TOTP two-factor authentication",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&MFASecret{}, &MFARecoveryCode{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&MFARecoveryCode{}, &MFASecret{})
+		},
+	}
+}
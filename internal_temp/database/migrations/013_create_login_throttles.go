@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginThrottle counts failed login attempts per throttle key
+// Keys are "email:<address>", "ip:<address>" or "mfa:<user id>"
+type LoginThrottle struct {
+	gorm.Model
+	Key           string `gorm:"not null;size:255;uniqueIndex"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt *time.Time
+	LockedUntil   *time.Time `gorm:"index"`
+}
+
+// Migration013CreateLoginThrottles creates the login_throttles table
+func Migration013CreateLoginThrottles() Migration {
+	return Migration{
+		ID:          "013_create_login_throttles",
+		Description: "Create login_throttles table for brute-force protection",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&LoginThrottle{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&LoginThrottle{})
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity links an external OAuth2 / OpenID Connect account to a user
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	User     *User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Provider string `gorm:"not null;size:64;uniqueIndex:idx_identity_provider_subject"`
+	Subject  string `gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	Email    *string
+}
+
+// OIDCLoginState stores the state, nonce and PKCE verifier of a login in progress
+// Only the SHA-256 hash of the state is stored; a state can be used once
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string    `gorm:"not null;size:64;uniqueIndex"`
+	Provider     string    `gorm:"not null;size:64"`
+	Nonce        string    `gorm:"not null;size:64"`
+	CodeVerifier string    `gorm:"not null;size:128"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
+
+// Migration014CreateUserIdentities creates the user_identities and oidc_login_states tables
+func Migration014CreateUserIdentities() Migration {
+	return Migration{
+		ID:          "014_create_user_identities",
+		Description: "Create user_identities and oidc_login_states tables for social login",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&UserIdentity{}, &OIDCLoginState{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&OIDCLoginState{}, &UserIdentity{})
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey stores hashed personal API keys
+// Scopes is a JSON array of "resource:action" permissions the key is limited to
+type APIKey struct {
+	gorm.Model
+	UserID     uint   `gorm:"not null;index"`
+	User       *User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name       string `gorm:"not null;size:100"`
+	Prefix     string `gorm:"not null;size:16"`
+	KeyHash    string `gorm:"not null;size:64;uniqueIndex"`
+	Scopes     string `gorm:"type:text;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time `gorm:"index"`
+}
+
+// Migration015CreateAPIKeys creates the api_keys table
+func Migration015CreateAPIKeys() Migration {
+	return Migration{
+		ID:          "015_create_api_keys",
+		Description: "Create api_keys table for scoped personal API keys",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&APIKey{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&APIKey{})
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession stores one row per login, tied to the refresh token family of that login
+type UserSession struct {
+	gorm.Model
+	UserID     uint      `gorm:"not null;index"`
+	User       *User     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FamilyID   string    `gorm:"not null;size:64;uniqueIndex"`
+	UserAgent  string    `gorm:"size:512"`
+	IP         string    `gorm:"column:ip;size:45"`
+	LastSeenAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+	RevokedAt  *time.Time
+}
+
+// RevokedTokenSession adds the revoked session to revoked_tokens
+type RevokedTokenSession struct {
+	SessionID *uint `gorm:"index"`
+}
+
+func (RevokedTokenSession) TableName() string {
+	return "revoked_tokens"
+}
+
+// Migration016CreateUserSessions creates the user_sessions table and adds
+// revoked_tokens.session_id so revoking a session revokes its access tokens
+func Migration016CreateUserSessions() Migration {
+	return Migration{
+		ID:          "016_create_user_sessions",
+		Description: "Create user_sessions table and add revoked_tokens.session_id",
+		Up: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&UserSession{}); err != nil {
+				return err
+			}
+
+			if !db.Migrator().HasColumn(&RevokedTokenSession{}, "SessionID") {
+				return db.Migrator().AddColumn(&RevokedTokenSession{}, "SessionID")
+			}
+			return nil
+		},
+		Down: func(db *gorm.DB) error {
+			if err := db.Migrator().DropColumn(&RevokedTokenSession{}, "SessionID"); err != nil {
+				return err
+			}
+			return db.Migrator().DropTable(&UserSession{})
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserStatusDetails adds the details of the current account status to users
+type UserStatusDetails struct {
+	StatusReason    *string `gorm:"type:text"`
+	StatusUntil     *time.Time
+	StatusChangedAt *time.Time
+	StatusChangedBy *uint
+}
+
+func (UserStatusDetails) TableName() string {
+	return "users"
+}
+
+// UserStatusChange records every change of a user's account status
+type UserStatusChange struct {
+	gorm.Model
+	UserID     uint    `gorm:"not null;index"`
+	User       *User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FromStatus string  `gorm:"not null;size:20"`
+	ToStatus   string  `gorm:"not null;size:20"`
+	Reason     *string `gorm:"type:text"`
+	Until      *time.Time
+	ChangedBy  *uint `gorm:"index"`
+}
+
+// Migration017AddUserStatus adds the status details columns to users and
+// creates the user_status_changes table
+func Migration017AddUserStatus() Migration {
+	return Migration{
+		ID:          "017_add_user_status",
+		Description: "Add users status details and create user_status_changes table",
+		Up: func(db *gorm.DB) error {
+			for _, column := range []string{"StatusReason", "StatusUntil", "StatusChangedAt", "StatusChangedBy"} {
+				if db.Migrator().HasColumn(&UserStatusDetails{}, column) {
+					continue
+				}
+				if err := db.Migrator().AddColumn(&UserStatusDetails{}, column); err != nil {
+					return err
+				}
+			}
+
+			if err := db.Exec("UPDATE users SET status = 'active' WHERE status IS NULL OR status = ''").Error; err != nil {
+				return err
+			}
+
+			return db.AutoMigrate(&UserStatusChange{})
+		},
+		Down: func(db *gorm.DB) error {
+			if err := db.Migrator().DropTable(&UserStatusChange{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"StatusReason", "StatusUntil", "StatusChangedAt", "StatusChangedBy"} {
+				if err := db.Migrator().DropColumn(&UserStatusDetails{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// UserAvatarMedia links the user's avatar to the media table
+type UserAvatarMedia struct {
+	AvatarMediaID *uint  `gorm:"index"`
+	AvatarMedia   *Media `gorm:"foreignKey:AvatarMediaID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+func (UserAvatarMedia) TableName() string {
+	return "users"
+}
+
+// EmailVerificationTokenPurpose tells verification tokens of the current
+// address apart from tokens that confirm a new address
+type EmailVerificationTokenPurpose struct {
+	Purpose string `gorm:"not null;size:20;default:'verify'"`
+}
+
+func (EmailVerificationTokenPurpose) TableName() string {
+	return "email_verification_tokens"
+}
+
+// Migration018AddProfileEditing adds users.avatar_media_id and
+// email_verification_tokens.purpose for the email change flow
+func Migration018AddProfileEditing() Migration {
+	return Migration{
+		ID:          "018_add_profile_editing",
+		Description: "Add users.avatar_media_id and email_verification_tokens.purpose",
+		Up: func(db *gorm.DB) error {
+			if !db.Migrator().HasColumn(&UserAvatarMedia{}, "AvatarMediaID") {
+				if err := db.Migrator().AddColumn(&UserAvatarMedia{}, "AvatarMediaID"); err != nil {
+					return err
+				}
+			}
+			if !db.Migrator().HasConstraint(&UserAvatarMedia{}, "AvatarMedia") {
+				if err := db.Migrator().CreateConstraint(&UserAvatarMedia{}, "AvatarMedia"); err != nil {
+					return err
+				}
+			}
+
+			if !db.Migrator().HasColumn(&EmailVerificationTokenPurpose{}, "Purpose") {
+				return db.Migrator().AddColumn(&EmailVerificationTokenPurpose{}, "Purpose")
+			}
+			return nil
+		},
+		Down: func(db *gorm.DB) error {
+			if err := db.Migrator().DropColumn(&EmailVerificationTokenPurpose{}, "Purpose"); err != nil {
+				return err
+			}
+			if err := db.Migrator().DropConstraint(&UserAvatarMedia{}, "AvatarMedia"); err != nil {
+				return err
+			}
+			return db.Migrator().DropColumn(&UserAvatarMedia{}, "AvatarMediaID")
+		},
+	}
+}
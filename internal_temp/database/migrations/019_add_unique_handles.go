@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// reservedHandles019 are the handles reserved when this migration was written, see ValidateHandle
+var reservedHandles019 = []string{
+	"about", "account", "admin", "administrator", "anonymous",
+	"api", "auth", "everyone", "help", "login",
+	"logout", "me", "mod", "moderator", "nogo",
+	"null", "official", "privacy", "profile", "register",
+	"root", "security", "settings", "signup", "staff",
+	"support", "system", "terms", "undefined", "user",
+	"users",
+}
+
+// maxDedupPasses bounds the renaming of duplicates, a pass only conflicts with names that were already taken
+const maxDedupPasses = 10
+
+// Migration019AddUniqueHandles makes usernames unique regardless of case
+// Existing usernames are first brought in line with ValidateHandle: other characters become
+// underscores, names longer than 30 characters are cut, and reserved or too short names become
+// user_<id>. Case-insensitive duplicates then keep the oldest account's handle; the others get
+// their user ID appended, cut so the result still fits in 30 characters
+func Migration019AddUniqueHandles() Migration {
+	return Migration{
+		ID:          "019_add_unique_handles",
+		Description: "Normalize and deduplicate usernames and add a case-insensitive unique index",
+		Up: func(db *gorm.DB) error {
+			if err := db.Exec(`
+				UPDATE users SET username = LEFT(REGEXP_REPLACE(username, '[^A-Za-z0-9_]', '_', 'g'), 30)
+				WHERE username IS NOT NULL AND username !~ '^[A-Za-z0-9_]{3,30}$'
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := db.Exec(`
+				UPDATE users SET username = 'user_' || id
+				WHERE username IS NOT NULL AND (LENGTH(username) < 3 OR LOWER(username) IN ?)
+			`, reservedHandles019).Error; err != nil {
+				return err
+			}
+
+			// A renamed user can take the name of another account, repeat until nothing is renamed
+			for pass := 0; ; pass++ {
+				if pass == maxDedupPasses {
+					return fmt.Errorf("usernames are still not unique after %d passes", maxDedupPasses)
+				}
+
+				result := db.Exec(`
+					UPDATE users SET username = LEFT(users.username, 30 - LENGTH('_' || users.id)) || '_' || users.id
+					FROM (
+						SELECT id, ROW_NUMBER() OVER (PARTITION BY LOWER(username) ORDER BY id) AS rn
+						FROM users
+						WHERE username IS NOT NULL
+					) duplicates
+					WHERE users.id = duplicates.id AND duplicates.rn > 1
+				`)
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					break
+				}
+			}
+
+			return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username))").Error
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Exec("DROP INDEX IF EXISTS idx_users_username_lower").Error
+		},
+	}
+}
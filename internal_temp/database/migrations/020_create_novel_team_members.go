@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NovelTeamMember stores per-novel team roles, mirrored as Casbin g2 rules in the novel's domain
+type NovelTeamMember struct {
+	gorm.Model
+	NovelID    uint   `gorm:"not null;uniqueIndex:idx_novel_team_member"`
+	Novel      *Novel `gorm:"foreignKey:NovelID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_novel_team_member;index"`
+	User       *User  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	RoleID     uint   `gorm:"not null"`
+	Role       *Role  `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Status     string `gorm:"not null;size:20"`
+	InvitedBy  *uint
+	AcceptedAt *time.Time
+}
+
+// Migration020CreateNovelTeamMembers creates the novel_team_members table
+func Migration020CreateNovelTeamMembers() Migration {
+	return Migration{
+		ID:          "020_create_novel_team_members",
+		Description: "Create novel_team_members table for per-novel team roles",
+		Up: func(db *gorm.DB) error {
+			return db.AutoMigrate(&NovelTeamMember{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropTable(&NovelTeamMember{})
+		},
+	}
+}
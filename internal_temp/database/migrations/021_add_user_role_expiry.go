@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRoleExpiry adds an optional expiry to role assignments
+type UserRoleExpiry struct {
+	ExpiresAt *time.Time `gorm:"index"`
+}
+
+func (UserRoleExpiry) TableName() string {
+	return "user_roles"
+}
+
+// Migration021AddUserRoleExpiry adds expires_at to user_roles for time-bound role assignments
+func Migration021AddUserRoleExpiry() Migration {
+	return Migration{
+		ID:          "021_add_user_role_expiry",
+		Description: "Add expires_at to user_roles",
+		Up: func(db *gorm.DB) error {
+			if db.Migrator().HasColumn(&UserRoleExpiry{}, "ExpiresAt") {
+				return nil
+			}
+			if err := db.Migrator().AddColumn(&UserRoleExpiry{}, "ExpiresAt"); err != nil {
+				return err
+			}
+			return db.Migrator().CreateIndex(&UserRoleExpiry{}, "ExpiresAt")
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropColumn(&UserRoleExpiry{}, "ExpiresAt")
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuthzAudit records every change of the Casbin policies
+type AuthzAudit struct {
+	ID          uint      `gorm:"primaryKey"`
+	CreatedAt   time.Time `gorm:"not null;index"`
+	ActorID     *uint     `gorm:"index"`
+	ActorSource string    `gorm:"not null;size:20"`
+	RequestID   string    `gorm:"size:64;index"`
+	Action      string    `gorm:"not null;size:50;index"`
+	Target      string    `gorm:"not null;size:255;index"`
+	Before      string    `gorm:"type:text;not null"`
+	After       string    `gorm:"type:text;not null"`
+}
+
+func (AuthzAudit) TableName() string {
+	return "authz_audit"
+}
+
+// Migration022CreateAuthzAudit creates the authz_audit table, with triggers refusing updates and deletes
+func Migration022CreateAuthzAudit() Migration {
+	return Migration{
+		ID:          "022_create_authz_audit",
+		Description: "Create append-only authz_audit table for policy changes",
+		Up: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&AuthzAudit{}); err != nil {
+				return err
+			}
+			if err := db.Exec(`
+				CREATE OR REPLACE FUNCTION authz_audit_append_only() RETURNS trigger AS $$
+				BEGIN
+					RAISE EXCEPTION 'authz_audit is append-only';
+				END;
+				$$ LANGUAGE plpgsql
+			`).Error; err != nil {
+				return err
+			}
+			if err := db.Exec(`
+				CREATE TRIGGER authz_audit_no_update_delete
+				BEFORE UPDATE OR DELETE ON authz_audit
+				FOR EACH ROW EXECUTE FUNCTION authz_audit_append_only()
+			`).Error; err != nil {
+				return err
+			}
+			return db.Exec(`
+				CREATE TRIGGER authz_audit_no_truncate
+				BEFORE TRUNCATE ON authz_audit
+				FOR EACH STATEMENT EXECUTE FUNCTION authz_audit_append_only()
+			`).Error
+		},
+		Down: func(db *gorm.DB) error {
+			if err := db.Migrator().DropTable(&AuthzAudit{}); err != nil {
+				return err
+			}
+			return db.Exec("DROP FUNCTION IF EXISTS authz_audit_append_only()").Error
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSessionAuthenticatedAt adds the login time to sessions
+type UserSessionAuthenticatedAt struct {
+	AuthenticatedAt *time.Time
+}
+
+func (UserSessionAuthenticatedAt) TableName() string {
+	return "user_sessions"
+}
+
+// Migration023AddUserSessionAuthenticatedAt adds authenticated_at to user_sessions, so credential
+// changes by users without a password can require a recent login
+// Existing sessions keep it null, their users log in again before such a change
+func Migration023AddUserSessionAuthenticatedAt() Migration {
+	return Migration{
+		ID:          "023_add_user_session_authenticated_at",
+		Description: "Add authenticated_at to user_sessions",
+		Up: func(db *gorm.DB) error {
+			if db.Migrator().HasColumn(&UserSessionAuthenticatedAt{}, "AuthenticatedAt") {
+				return nil
+			}
+			return db.Migrator().AddColumn(&UserSessionAuthenticatedAt{}, "AuthenticatedAt")
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Migrator().DropColumn(&UserSessionAuthenticatedAt{}, "AuthenticatedAt")
+		},
+	}
+}
@@ -32,6 +32,22 @@ func GetAllMigrations() []Migration {
 		Migration005CreateChapters(),
 		Migration006CreateGenresAndTags(),
 		Migration007AddNovelGenresAndTags(),
+		Migration008CreateRefreshTokens(),
+		Migration009CreateRevokedTokens(),
+		Migration010AddEmailVerification(),
+		Migration011CreatePasswordResetTokens(),
+		Migration012CreateMFA(),
+		Migration013CreateLoginThrottles(),
+		Migration014CreateUserIdentities(),
+		Migration015CreateAPIKeys(),
+		Migration016CreateUserSessions(),
+		Migration017AddUserStatus(),
+		Migration018AddProfileEditing(),
+		Migration019AddUniqueHandles(),
+		Migration020CreateNovelTeamMembers(),
+		Migration021AddUserRoleExpiry(),
+		Migration022CreateAuthzAudit(),
+		Migration023AddUserSessionAuthenticatedAt(),
 	}
 }
 
@@ -7,9 +7,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// SeedCasbinPolicies seeds Casbin permissions using Casbin's API
-// This runs automatically on server start and updates permissions
-// It will add new permissions without duplicating existing ones
+// SeedCasbinPolicies seeds Casbin permissions from the policy file
+// This runs automatically on server start and adds the permissions missing from the database.
+// Roles the file doesn't manage are only seeded while they have no rules, so permissions removed
+// through the API stay removed. It never removes rules, that is left to `cmd/authz apply --confirm`
 func SeedCasbinPolicies(db *gorm.DB) error {
 	log.Println("🌱 Auto-seeding Casbin permissions...")
 
@@ -22,84 +23,52 @@ func SeedCasbinPolicies(db *gorm.DB) error {
 
 	casbin := casbinService.NewCasbinService(db)
 
-	// ==========================================
-	// ADMIN ROLE - Full Access
-	// ==========================================
-	adminPerms := []struct {
-		resource string
-		action   string
-	}{
-		{"users", "read"},
-		{"users", "write"},
-		{"users", "delete"},
-		{"novels", "read"},
-		{"novels", "write"},
-		{"novels", "delete"},
-		{"chapters", "read"},
-		{"chapters", "write"},
-		{"chapters", "delete"},
-		{"genres", "read"},
-		{"genres", "write"},
-		{"genres", "delete"},
-		{"tags", "read"},
-		{"tags", "write"},
-		{"tags", "delete"},
-		{"roles", "read"},
-		{"roles", "write"},
-		{"media", "read"},
-		{"media", "write"},
-		{"media", "delete"},
+	// Role permissions and inheritance are declared in the policy file
+	file, err := casbinService.LoadPolicyFile(casbinService.DefaultPolicyPath)
+	if err != nil {
+		return err
 	}
 
-	for _, perm := range adminPerms {
-		if err := casbin.AddPermissionForRole("admin", perm.resource, perm.action); err != nil {
-			log.Printf("⚠️  Warning: Failed to add admin permission %s:%s - %v", perm.resource, perm.action, err)
-		}
+	diff, err := casbin.DiffPolicyFile(file)
+	if err != nil {
+		return err
 	}
 
-	// ==========================================
-	// AUTHOR ROLE - Content Creation
-	// ==========================================
-	authorPerms := []struct {
-		resource string
-		action   string
-	}{
-		{"novels", "read"},
-		{"novels", "write"},
-		{"chapters", "read"},
-		{"chapters", "write"},
-		{"genres", "read"},
-		{"tags", "read"},
-		{"profile", "read"},
-		{"profile", "write"},
-		{"media", "write"},
+	// Seeding only adds the missing rules, stale ones are removed by reviewing the diff with cmd/authz apply
+	unseeded := make(map[string]bool)
+	for _, role := range file.Roles() {
+		if file.IsManaged(role) {
+			continue
+		}
+		permissions, err := casbin.GetPermissionsForRole(role)
+		if err != nil {
+			return err
+		}
+		parents, err := casbin.GetParentRoles(role)
+		if err != nil {
+			return err
+		}
+		unseeded[role] = len(permissions) == 0 && len(parents) == 0
 	}
-
-	for _, perm := range authorPerms {
-		if err := casbin.AddPermissionForRole("author", perm.resource, perm.action); err != nil {
-			log.Printf("⚠️  Warning: Failed to add author permission %s:%s - %v", perm.resource, perm.action, err)
+	seedable := func(rules [][]string) [][]string {
+		var seeded [][]string
+		for _, rule := range rules {
+			if file.IsManaged(rule[0]) || unseeded[rule[0]] {
+				seeded = append(seeded, rule)
+			}
 		}
+		return seeded
 	}
-
-	// ==========================================
-	// USER ROLE - Read Only
-	// ==========================================
-	userPerms := []struct {
-		resource string
-		action   string
-	}{
-		{"novels", "read"},
-		{"chapters", "read"},
-		{"genres", "read"},
-		{"tags", "read"},
-		{"profile", "read"},
-		{"profile", "write"},
+	missing := &casbinService.PolicyDiff{
+		AddPolicies:    seedable(diff.AddPolicies),
+		AddInheritance: seedable(diff.AddInheritance),
+	}
+	if err := casbin.ApplyPolicyDiff(missing); err != nil {
+		log.Printf("⚠️  Warning: Failed to add policies from %s - %v", casbinService.DefaultPolicyPath, err)
 	}
 
-	for _, perm := range userPerms {
-		if err := casbin.AddPermissionForRole("user", perm.resource, perm.action); err != nil {
-			log.Printf("⚠️  Warning: Failed to add user permission %s:%s - %v", perm.resource, perm.action, err)
-		}
+	if stale := len(diff.RemovePolicies) + len(diff.RemoveInheritance); stale > 0 {
+		log.Printf("⚠️  %d Casbin rules are not in %s, review them with: go run ./cmd/authz apply", stale, casbinService.DefaultPolicyPath)
 	}
 
 	// Get final count
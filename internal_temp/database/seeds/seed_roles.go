@@ -18,9 +18,13 @@ func SeedRoles(db *gorm.DB) error {
 	log.Println("🌱 Seeding roles...")
 
 	roles := []Role{
-		{Name: "admin", Description: strPtr("Administrator with full access to all resources")},
-		{Name: "author", Description: strPtr("Content creator who can write and manage novels and chapters")},
+		{Name: "admin", Description: strPtr("Administrator with full access to all resources, inherits moderator")},
+		{Name: "moderator", Description: strPtr("Moderator who can edit and remove any content, inherits author")},
+		{Name: "author", Description: strPtr("Content creator who can write and manage novels and chapters, inherits user")},
 		{Name: "user", Description: strPtr("Regular user with read access")},
+		{Name: "lead", Description: strPtr("Novel team lead who manages the team, only within the novel's team")},
+		{Name: "editor", Description: strPtr("Novel team editor who can edit the novel and its chapters, only within the novel's team")},
+		{Name: "translator", Description: strPtr("Novel team translator who can write chapters, only within the novel's team")},
 	}
 
 	for _, role := range roles {
@@ -7,6 +7,7 @@ import (
 	commonDto "github.com/FeisalDy/nogo/internal/common/dto"
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/novel/dto"
 	"github.com/FeisalDy/nogo/internal/novel/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -39,6 +40,51 @@ func (h *NovelHandler) GetNovelByID(c *gin.Context) {
 	utils.RespondSuccess(c, http.StatusOK, novel)
 }
 
+// UpdateNovel updates a novel
+// Access is checked by OwnershipMiddleware, so authors can only update their own novels
+func (h *NovelHandler) UpdateNovel(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 32)
+
+	if err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeInvalidParam)
+		return
+	}
+
+	var req dto.UpdateNovelDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeValidationFailed)
+		return
+	}
+
+	novel, err := h.novelService.UpdateNovel(uint(id), &req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, novel)
+}
+
+// DeleteNovel deletes a novel
+// Access is checked by OwnershipMiddleware, so authors can only delete their own novels
+func (h *NovelHandler) DeleteNovel(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 32)
+
+	if err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeInvalidParam)
+		return
+	}
+
+	if err := h.novelService.DeleteNovel(uint(id)); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, nil, "Novel deleted successfully")
+}
+
 // GetAllNovels retrieves all novels with cursor-based pagination
 // Query params:
 //   - cursor: base64-encoded cursor for pagination (optional)
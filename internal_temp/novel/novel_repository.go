@@ -83,3 +83,27 @@ func (r *NovelRepository) GetAllWithTranslationCursor(
 
 	return utils.PaginateWithIDGetter[Novel](baseQuery, req)
 }
+
+// GetOwnerIDs returns the users who own a novel: its creator and the translators of its translations
+// Returns gorm.ErrRecordNotFound if the novel doesn't exist
+func (r *NovelRepository) GetOwnerIDs(id uint) ([]uint, error) {
+	var novel Novel
+	if err := r.db.Select("id", "created_by").First(&novel, id).Error; err != nil {
+		return nil, err
+	}
+
+	var translatorIDs []uint
+	err := r.db.Model(&NovelTranslation{}).
+		Where("novel_id = ? AND translator_id IS NOT NULL", id).
+		Distinct().
+		Pluck("translator_id", &translatorIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ownerIDs := translatorIDs
+	if novel.CreatedBy != nil {
+		ownerIDs = append(ownerIDs, *novel.CreatedBy)
+	}
+	return ownerIDs, nil
+}
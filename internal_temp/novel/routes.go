@@ -1,6 +1,7 @@
 package novel
 
 import (
+	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/novel/handler"
 	"github.com/FeisalDy/nogo/internal/novel/repository"
 	"github.com/FeisalDy/nogo/internal/novel/service"
@@ -15,10 +16,14 @@ func RegisterRoutes(db *gorm.DB, router *gin.RouterGroup) {
 
 	novelRoutes := router.Group("/")
 	{
+		routes := middleware.DeclareRoutes(novelRoutes)
+
 		// Single novel operations
-		novelRoutes.GET("/:id", novelHandler.GetNovelByID)
+		routes.Public().GET("/:id", novelHandler.GetNovelByID)
+		routes.Ownership("novels", "write", "id", novelRepo.GetOwnerIDs).PUT("/:id", middleware.AuthMiddleware(), middleware.NovelDomain("id"), novelHandler.UpdateNovel)
+		routes.Ownership("novels", "delete", "id", novelRepo.GetOwnerIDs).DELETE("/:id", middleware.AuthMiddleware(), middleware.NovelDomain("id"), novelHandler.DeleteNovel)
 
 		// Cursor-based pagination endpoints
-		novelRoutes.GET("", novelHandler.GetAllNovels) // GET /novels?cursor=...&limit=20
+		routes.Public().GET("", novelHandler.GetAllNovels) // GET /novels?cursor=...&limit=20
 	}
 }
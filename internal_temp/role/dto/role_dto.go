@@ -36,3 +36,36 @@ type UserDTO struct {
 	Status    string  `json:"status"`
 	AvatarURL *string `json:"avatar_url,omitempty"`
 }
+
+// PermissionDTO is a single resource:action permission
+type PermissionDTO struct {
+	Resource string `json:"resource" validate:"required,max=50"`
+	Action   string `json:"action" validate:"required,max=50"`
+}
+
+// SetRolePermissionsDTO replaces all permissions of a role
+type SetRolePermissionsDTO struct {
+	Permissions []PermissionDTO `json:"permissions" validate:"dive"`
+}
+
+// AddParentRoleDTO makes a role inherit the permissions of another role
+type AddParentRoleDTO struct {
+	RoleID uint `json:"role_id" validate:"required"`
+}
+
+// EffectivePermissionDTO is a permission together with the role that grants it
+type EffectivePermissionDTO struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Source   string `json:"source"`
+}
+
+// RolePermissionsDTO represents the permissions granted to a role
+// Permissions are granted to the role directly, EffectivePermissions also include inherited ones
+type RolePermissionsDTO struct {
+	RoleID               uint                     `json:"role_id"`
+	Role                 string                   `json:"role"`
+	Parents              []string                 `json:"parents"`
+	Permissions          []PermissionDTO          `json:"permissions"`
+	EffectivePermissions []EffectivePermissionDTO `json:"effective_permissions"`
+}
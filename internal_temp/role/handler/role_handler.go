@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/FeisalDy/nogo/internal/role/dto"
 	"github.com/FeisalDy/nogo/internal/role/service"
@@ -95,7 +96,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	role, err := h.roleService.UpdateRole(uint(id), req)
+	role, err := h.roleService.WithActor(middleware.GetAuditActor(c)).UpdateRole(uint(id), req)
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
@@ -112,7 +113,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.DeleteRole(uint(id)); err != nil {
+	if err := h.roleService.WithActor(middleware.GetAuditActor(c)).DeleteRole(uint(id)); err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/role/dto"
+	"github.com/FeisalDy/nogo/internal/role/service"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// RolePermissionHandler handles requests about the permissions granted to roles and role inheritance
+type RolePermissionHandler struct {
+	rolePermissionService *service.RolePermissionService
+	validator             *validator.Validate
+}
+
+// NewRolePermissionHandler creates a new instance of RolePermissionHandler
+func NewRolePermissionHandler(rolePermissionService *service.RolePermissionService) *RolePermissionHandler {
+	return &RolePermissionHandler{
+		rolePermissionService: rolePermissionService,
+		validator:             validator.New(),
+	}
+}
+
+// GetRegistry lists the resources and actions that can be granted to a role
+// GET /api/v1/roles/permissions
+func (h *RolePermissionHandler) GetRegistry(c *gin.Context) {
+	utils.RespondSuccess(c, http.StatusOK, h.rolePermissionService.GetRegistry(), "Permission registry retrieved successfully")
+}
+
+// GetPermissions lists the permissions granted to a role
+// GET /api/v1/roles/:id/permissions
+func (h *RolePermissionHandler) GetPermissions(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.rolePermissionService.GetPermissions(roleID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, permissions, "Role permissions retrieved successfully")
+}
+
+// AddPermission grants a permission to a role
+// POST /api/v1/roles/:id/permissions
+func (h *RolePermissionHandler) AddPermission(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PermissionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	permissions, err := h.rolePermissionService.WithActor(middleware.GetAuditActor(c)).AddPermission(roleID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusCreated, permissions, "Permission added to role successfully")
+}
+
+// SetPermissions replaces all permissions of a role
+// PUT /api/v1/roles/:id/permissions
+func (h *RolePermissionHandler) SetPermissions(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetRolePermissionsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	permissions, err := h.rolePermissionService.WithActor(middleware.GetAuditActor(c)).SetPermissions(roleID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, permissions, "Role permissions updated successfully")
+}
+
+// RemovePermission revokes a permission from a role
+// DELETE /api/v1/roles/:id/permissions/:resource/:action
+func (h *RolePermissionHandler) RemovePermission(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	req := dto.PermissionDTO{
+		Resource: c.Param("resource"),
+		Action:   c.Param("action"),
+	}
+
+	permissions, err := h.rolePermissionService.WithActor(middleware.GetAuditActor(c)).RemovePermission(roleID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, permissions, "Permission removed from role successfully")
+}
+
+// AddParentRole makes a role inherit all permissions of another role
+// POST /api/v1/roles/:id/parents
+func (h *RolePermissionHandler) AddParentRole(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddParentRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.RespondValidationError(c, err, errors.ErrCodeRoleValidation)
+		return
+	}
+
+	permissions, err := h.rolePermissionService.WithActor(middleware.GetAuditActor(c)).AddParentRole(roleID, req.RoleID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusCreated, permissions, "Parent role added successfully")
+}
+
+// RemoveParentRole stops a role from inheriting another role
+// DELETE /api/v1/roles/:id/parents/:parent_id
+func (h *RolePermissionHandler) RemoveParentRole(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	parentID, err := strconv.ParseUint(c.Param("parent_id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return
+	}
+
+	permissions, err := h.rolePermissionService.WithActor(middleware.GetAuditActor(c)).RemoveParentRole(roleID, uint(parentID))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, permissions, "Parent role removed successfully")
+}
+
+// parseRoleID reads the role ID route parameter, responding with an error if it is invalid
+func parseRoleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithAppError(c, errors.ErrInvalidParam.WithDetails(map[string]any{
+			"reason": err.Error(),
+		}))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -54,6 +54,11 @@ func (r *RoleRepository) Delete(id uint) error {
 	return r.db.Delete(&roleModel.Role{}, id).Error
 }
 
+// DeleteUserRoles removes the role from every user by deleting its user_roles rows
+func (r *RoleRepository) DeleteUserRoles(roleID uint) error {
+	return r.db.Where("role_id = ?", roleID).Delete(&model.UserRole{}).Error
+}
+
 // GetRoleWithUsers gets a role and all users with that role
 func (r *RoleRepository) GetRoleWithUsers(roleID uint) (*roleModel.Role, []userModel.User, error) {
 	var role roleModel.Role
@@ -1,6 +1,7 @@
 package role
 
 import (
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/middleware"
 	"github.com/FeisalDy/nogo/internal/role/handler"
 	"github.com/FeisalDy/nogo/internal/role/repository"
@@ -11,17 +12,33 @@ import (
 
 func RegisterRoutes(db *gorm.DB, router *gin.RouterGroup) {
 	roleRepository := repository.NewRoleRepository(db)
-	roleService := service.NewRoleService(roleRepository)
+	casbin := casbinService.NewCasbinService(db)
+	roleService := service.NewRoleService(roleRepository, casbin)
+	rolePermissionService := service.NewRolePermissionService(roleRepository, casbin)
 	roleHandler := handler.NewRoleHandler(roleService)
+	rolePermissionHandler := handler.NewRolePermissionHandler(rolePermissionService)
 
 	roleRoutes := router.Group("/")
 	roleRoutes.Use(middleware.AuthMiddleware())
 	{
+		routes := middleware.DeclareRoutes(roleRoutes)
+
 		// CRUD operations
-		roleRoutes.POST("", roleHandler.CreateRole)       // Create a new role
-		roleRoutes.GET("", roleHandler.GetAllRoles)       // Get all roles
-		roleRoutes.GET("/:id", roleHandler.GetRole)       // Get a role by ID
-		roleRoutes.PUT("/:id", roleHandler.UpdateRole)    // Update a role
-		roleRoutes.DELETE("/:id", roleHandler.DeleteRole) // Delete a role
+		routes.Permission("roles", "write").POST("", roleHandler.CreateRole)       // Create a new role
+		routes.Permission("roles", "read").GET("", roleHandler.GetAllRoles)        // Get all roles
+		routes.Permission("roles", "read").GET("/:id", roleHandler.GetRole)        // Get a role by ID
+		routes.Permission("roles", "write").PUT("/:id", roleHandler.UpdateRole)    // Update a role
+		routes.Permission("roles", "write").DELETE("/:id", roleHandler.DeleteRole) // Delete a role
+
+		// Permission management
+		routes.Permission("roles", "read").GET("/permissions", rolePermissionHandler.GetRegistry)
+		routes.Permission("roles", "read").GET("/:id/permissions", rolePermissionHandler.GetPermissions)
+		routes.Permission("roles", "write").POST("/:id/permissions", rolePermissionHandler.AddPermission)
+		routes.Permission("roles", "write").PUT("/:id/permissions", rolePermissionHandler.SetPermissions)
+		routes.Permission("roles", "write").DELETE("/:id/permissions/:resource/:action", rolePermissionHandler.RemovePermission)
+
+		// Role inheritance
+		routes.Permission("roles", "write").POST("/:id/parents", rolePermissionHandler.AddParentRole)
+		routes.Permission("roles", "write").DELETE("/:id/parents/:parent_id", rolePermissionHandler.RemoveParentRole)
 	}
 }
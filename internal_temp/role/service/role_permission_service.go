@@ -0,0 +1,259 @@
+package service
+
+import (
+	stdErrors "errors"
+	"slices"
+
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/role/dto"
+	"github.com/FeisalDy/nogo/internal/role/model"
+	"github.com/FeisalDy/nogo/internal/role/repository"
+	"gorm.io/gorm"
+)
+
+// RolePermissionService manages the Casbin permissions granted to roles and the roles they inherit from
+type RolePermissionService struct {
+	roleRepo      *repository.RoleRepository
+	casbinService *casbinService.CasbinService
+}
+
+// NewRolePermissionService creates a new instance of RolePermissionService
+func NewRolePermissionService(roleRepo *repository.RoleRepository, casbin *casbinService.CasbinService) *RolePermissionService {
+	return &RolePermissionService{
+		roleRepo:      roleRepo,
+		casbinService: casbin,
+	}
+}
+
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *RolePermissionService) WithActor(actor casbinService.AuditActor) *RolePermissionService {
+	service := *s
+	service.casbinService = s.casbinService.WithActor(actor)
+	return &service
+}
+
+// GetPermissions returns the permissions granted to a role
+func (s *RolePermissionService) GetPermissions(roleID uint) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// AddPermission grants a registered permission to a role
+func (s *RolePermissionService) AddPermission(roleID uint, req dto.PermissionDTO) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return nil, err
+	}
+	if err := validatePermission(req); err != nil {
+		return nil, err
+	}
+
+	has, err := s.casbinService.HasPermissionForRole(role.Name, req.Resource, req.Action)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return nil, errors.ErrRolePermExists
+	}
+
+	if err := s.casbinService.AddPermissionForRole(role.Name, req.Resource, req.Action); err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// SetPermissions replaces all permissions of a role
+// Nothing is changed if any of the permissions is not registered
+func (s *RolePermissionService) SetPermissions(roleID uint, req dto.SetRolePermissionsDTO) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return nil, err
+	}
+
+	permissions := make([][]string, 0, len(req.Permissions))
+	seen := make(map[dto.PermissionDTO]bool, len(req.Permissions))
+	for _, perm := range req.Permissions {
+		if err := validatePermission(perm); err != nil {
+			return nil, err
+		}
+		if seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		permissions = append(permissions, []string{perm.Resource, perm.Action})
+	}
+
+	if err := s.casbinService.SetPermissionsForRole(role.Name, permissions); err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// RemovePermission revokes a permission from a role
+func (s *RolePermissionService) RemovePermission(roleID uint, req dto.PermissionDTO) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return nil, err
+	}
+
+	has, err := s.casbinService.HasPermissionForRole(role.Name, req.Resource, req.Action)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.ErrRolePermNotFound
+	}
+
+	if err := s.casbinService.RemovePermissionForRole(role.Name, req.Resource, req.Action); err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// AddParentRole makes a role inherit all permissions of another role
+func (s *RolePermissionService) AddParentRole(roleID, parentID uint) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return nil, err
+	}
+	parent, err := s.getRole(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := s.casbinService.GetParentRoles(role.Name)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(parents, parent.Name) {
+		return nil, errors.ErrRoleParentExists
+	}
+
+	if err := s.casbinService.AddParentRole(role.Name, parent.Name); err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// RemoveParentRole stops a role from inheriting another role
+func (s *RolePermissionService) RemoveParentRole(roleID, parentID uint) (*dto.RolePermissionsDTO, error) {
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return nil, err
+	}
+	parent, err := s.getRole(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := s.casbinService.GetParentRoles(role.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(parents, parent.Name) {
+		return nil, errors.ErrRoleParentNotFound
+	}
+
+	if err := s.casbinService.RemoveParentRole(role.Name, parent.Name); err != nil {
+		return nil, err
+	}
+	return s.toDTO(role)
+}
+
+// GetRegistry returns the resources and actions that can be granted
+func (s *RolePermissionService) GetRegistry() []casbinService.ResourcePermissions {
+	return casbinService.KnownPermissions()
+}
+
+func (s *RolePermissionService) getRole(roleID uint) (*model.Role, error) {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *RolePermissionService) toDTO(role *model.Role) (*dto.RolePermissionsDTO, error) {
+	policies, err := s.casbinService.GetPermissionsForRole(role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]dto.PermissionDTO, 0, len(policies))
+	for _, policy := range policies {
+		if len(policy) >= 3 {
+			permissions = append(permissions, dto.PermissionDTO{Resource: policy[1], Action: policy[2]})
+		}
+	}
+
+	parents, err := s.casbinService.GetParentRoles(role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	effective, err := s.casbinService.GetEffectivePermissions([]string{role.Name})
+	if err != nil {
+		return nil, err
+	}
+	effectiveDTOs := make([]dto.EffectivePermissionDTO, 0, len(effective))
+	for _, perm := range effective {
+		effectiveDTOs = append(effectiveDTOs, dto.EffectivePermissionDTO{
+			Resource: perm.Resource,
+			Action:   perm.Action,
+			Source:   perm.Source,
+		})
+	}
+
+	return &dto.RolePermissionsDTO{
+		RoleID:               role.ID,
+		Role:                 role.Name,
+		Parents:              parents,
+		Permissions:          permissions,
+		EffectivePermissions: effectiveDTOs,
+	}, nil
+}
+
+// ensureNotFileManaged refuses changes to the roles the policy file manages, apply would revert them
+func ensureNotFileManaged(roleNames ...string) error {
+	for _, roleName := range roleNames {
+		if casbinService.IsFileManagedRole(roleName) {
+			return errors.NewAppError(errors.ErrCodeRoleFileManaged, errors.ErrRoleFileManaged.Message).WithDetails(map[string]any{
+				"role": roleName,
+			})
+		}
+	}
+	return nil
+}
+
+// validatePermission checks the permission against the registry of known resources and actions
+func validatePermission(perm dto.PermissionDTO) error {
+	if casbinService.IsKnownPermission(perm.Resource, perm.Action) {
+		return nil
+	}
+	return errors.NewAppError(errors.ErrCodeRolePermUnknown, "Unknown permission").WithDetails(map[string]any{
+		"resource": perm.Resource,
+		"action":   perm.Action,
+	})
+}
@@ -1,22 +1,33 @@
 package service
 
 import (
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/role/dto"
 	"github.com/FeisalDy/nogo/internal/role/model"
 	"github.com/FeisalDy/nogo/internal/role/repository"
+	"gorm.io/gorm"
 )
 
 type RoleService struct {
-	roleRepo *repository.RoleRepository
+	roleRepo      *repository.RoleRepository
+	casbinService *casbinService.CasbinService
 }
 
-func NewRoleService(roleRepo *repository.RoleRepository) *RoleService {
+func NewRoleService(roleRepo *repository.RoleRepository, casbin *casbinService.CasbinService) *RoleService {
 	return &RoleService{
-		roleRepo: roleRepo,
+		roleRepo:      roleRepo,
+		casbinService: casbin,
 	}
 }
 
+// WithActor returns a copy of the service that records its policy changes as made by the actor
+func (s *RoleService) WithActor(actor casbinService.AuditActor) *RoleService {
+	service := *s
+	service.casbinService = s.casbinService.WithActor(actor)
+	return &service
+}
+
 func (s *RoleService) CreateRole(req dto.CreateRoleDTO) (*model.Role, error) {
 	exists, err := s.roleRepo.ExistsByName(req.Name)
 	if err != nil {
@@ -72,6 +83,9 @@ func (s *RoleService) GetAllRoles() ([]model.Role, error) {
 	return roles, nil
 }
 
+// UpdateRole updates a role's name and description
+// A new name is applied to the role's Casbin rules in the same transaction, so its permissions
+// and assignments follow it. Team roles keep their names, they are assigned by name within teams
 func (s *RoleService) UpdateRole(id uint, req dto.UpdateRoleDTO) (*model.Role, error) {
 	role, err := s.roleRepo.GetByID(id)
 	if err != nil {
@@ -81,7 +95,14 @@ func (s *RoleService) UpdateRole(id uint, req dto.UpdateRoleDTO) (*model.Role, e
 		return nil, errors.ErrRoleNotFound
 	}
 
+	oldName := role.Name
 	if req.Name != nil && *req.Name != role.Name {
+		if casbinService.IsTeamRole(role.Name) {
+			return nil, errors.ErrRoleNameFixed
+		}
+		if err := ensureNotFileManaged(role.Name, *req.Name); err != nil {
+			return nil, err
+		}
 		exists, err := s.roleRepo.ExistsByName(*req.Name)
 		if err != nil {
 			return nil, err
@@ -96,13 +117,28 @@ func (s *RoleService) UpdateRole(id uint, req dto.UpdateRoleDTO) (*model.Role, e
 		role.Description = req.Description
 	}
 
-	if err := s.roleRepo.Update(role); err != nil {
+	if role.Name == oldName {
+		if err := s.roleRepo.Update(role); err != nil {
+			return nil, err
+		}
+		return role, nil
+	}
+
+	err = s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		if err := s.roleRepo.WithTx(tx).Update(role); err != nil {
+			return err
+		}
+		return casbin.UpdateRoleName(oldName, role.Name)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return role, nil
 }
 
+// DeleteRole deletes a role together with its user assignments and Casbin rules
+// Everything is removed in one transaction, so users can't keep a role that no longer exists
 func (s *RoleService) DeleteRole(id uint) error {
 	role, err := s.roleRepo.GetByID(id)
 	if err != nil {
@@ -111,6 +147,18 @@ func (s *RoleService) DeleteRole(id uint) error {
 	if role == nil {
 		return errors.ErrRoleNotFound
 	}
+	if err := ensureNotFileManaged(role.Name); err != nil {
+		return err
+	}
 
-	return s.roleRepo.Delete(id)
+	return s.casbinService.Transaction(func(tx *gorm.DB, casbin *casbinService.CasbinService) error {
+		roleRepo := s.roleRepo.WithTx(tx)
+		if err := roleRepo.DeleteUserRoles(role.ID); err != nil {
+			return err
+		}
+		if err := roleRepo.Delete(role.ID); err != nil {
+			return err
+		}
+		return casbin.DeleteRole(role.Name)
+	})
 }
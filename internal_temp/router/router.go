@@ -1,8 +1,15 @@
 package router
 
 import (
+	"fmt"
+	"log"
+	"strings"
+
 	"github.com/FeisalDy/nogo/config"
 	"github.com/FeisalDy/nogo/internal/application"
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/FeisalDy/nogo/internal/novel"
 	"github.com/FeisalDy/nogo/internal/role"
 	"github.com/FeisalDy/nogo/internal/user"
@@ -11,19 +18,30 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(db *gorm.DB, cfg config.AppConfig) *gin.Engine {
+func SetupRoutes(db *gorm.DB, cfg config.Config) *gin.Engine {
 	r := gin.Default()
 
+	// The client IP keys login throttling, so X-Forwarded-For is only read from configured proxies
+	if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	r.Use(middleware.RequestID())
+
+	// Public keys for verifying access tokens (empty with HS256)
+	middleware.DeclareRoutes(&r.RouterGroup).Public().GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(200, utils.GetJWKS())
+	})
+
 	v1 := r.Group("/api/v1")
 	{
-		v1.GET("/ping", func(c *gin.Context) {
+		middleware.DeclareRoutes(v1).Public().GET("/ping", func(c *gin.Context) {
 			c.JSON(200, gin.H{
 				"message": "pong",
 				"status":  "healthy",
 			})
 		})
 
-		application.RegisterRoutes(db, v1)
+		application.RegisterRoutes(db, v1, cfg)
 
 		userRoutes := v1.Group("/users")
 		user.RegisterRoutes(db, userRoutes)
@@ -38,8 +56,44 @@ func SetupRoutes(db *gorm.DB, cfg config.AppConfig) *gin.Engine {
 	return r
 }
 
+// VerifyRoutes checks that every route declares its access and only requires permissions from the
+// permission registry. Permissions that no role holds are logged, as nobody can use those routes
+func VerifyRoutes(r *gin.Engine, db *gorm.DB) error {
+	casbin := casbinService.NewCasbinService(db)
+
+	var problems []string
+	for _, route := range r.Routes() {
+		permission, ok := casbinService.GetRoutePermission(route.Method, route.Path)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s %s has no declared permission", route.Method, route.Path))
+			continue
+		}
+		if err := casbinService.VerifyRoutePermission(permission); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if permission.Resource == "" {
+			continue
+		}
+
+		roles, err := casbin.GetRolesWithRoutePermission(permission)
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			log.Printf("⚠️  No role holds %s:%s, %s %s can't be used by anyone",
+				permission.Resource, strings.Join(permission.Actions(), "|"), route.Method, route.Path)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid route permissions:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
 // SetupRoutesWithMiddleware sets up routes with additional middleware
-func SetupRoutesWithMiddleware(db *gorm.DB, cfg config.AppConfig, middlewares ...gin.HandlerFunc) *gin.Engine {
+func SetupRoutesWithMiddleware(db *gorm.DB, cfg config.Config, middlewares ...gin.HandlerFunc) *gin.Engine {
 	r := SetupRoutes(db, cfg)
 
 	// Apply global middleware
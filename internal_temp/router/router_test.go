@@ -0,0 +1,136 @@
+package router
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	casbinService "github.com/FeisalDy/nogo/internal/common/casbin"
+	"github.com/FeisalDy/nogo/internal/common/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	initEnforcerOnce sync.Once
+	enforcerDB       *gorm.DB
+)
+
+// testDB returns a database for VerifyRoutes and resets the package enforcer to no policies
+// The enforcer is created once per test binary and keeps its rules in memory
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	initEnforcerOnce.Do(func() {
+		db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		if _, err := casbinService.InitCasbin(db, "../../config/casbin/model.conf"); err != nil {
+			t.Fatalf("init casbin: %v", err)
+		}
+		enforcerDB = db
+	})
+
+	enforcer := casbinService.GetEnforcer()
+	enforcer.ClearPolicy()
+	if err := enforcer.BuildRoleLinks(); err != nil {
+		t.Fatalf("build role links: %v", err)
+	}
+	return enforcerDB
+}
+
+func TestVerifyRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(c *gin.Context) {}
+
+	tests := []struct {
+		name     string
+		register func(group *gin.RouterGroup)
+		policies [][]string // [role, resource, action]
+		wantErr  string     // part of the error, empty when the routes are valid
+		wantLog  string     // part of the log, empty when nothing is logged
+	}{
+		{
+			name: "declared routes",
+			register: func(group *gin.RouterGroup) {
+				routes := middleware.DeclareRoutes(group)
+				routes.Public().GET("/ping", handler)
+				routes.Authenticated().GET("/me", handler)
+				routes.Permission("roles", "read").GET("/roles", handler)
+			},
+			policies: [][]string{{"admin", "roles", "read"}},
+		},
+		{
+			name: "ownership held with the own scope",
+			register: func(group *gin.RouterGroup) {
+				middleware.DeclareRoutes(group).Ownership("novels", "write", "id", nil).PUT("/novels/:id", handler)
+			},
+			policies: [][]string{{"author", "novels", "write:own"}},
+		},
+		{
+			name: "route without a declaration",
+			register: func(group *gin.RouterGroup) {
+				middleware.DeclareRoutes(group).Public().GET("/ping", handler)
+				group.GET("/undeclared", handler)
+			},
+			wantErr: "GET /undeclared has no declared permission",
+		},
+		{
+			name: "unknown permission",
+			register: func(group *gin.RouterGroup) {
+				middleware.DeclareRoutes(group).Permission("roles", "delete").DELETE("/roles/:id", handler)
+			},
+			wantErr: "DELETE /roles/:id requires unknown permission roles:delete",
+		},
+		{
+			name: "ownership with an unscoped action",
+			register: func(group *gin.RouterGroup) {
+				middleware.DeclareRoutes(group).Ownership("users", "write", "id", nil).PUT("/users/:id", handler)
+			},
+			wantErr: "PUT /users/:id requires unknown permission users:write:any",
+		},
+		{
+			name: "permission no role holds",
+			register: func(group *gin.RouterGroup) {
+				middleware.DeclareRoutes(group).Permission("authz", "read").GET("/authz", handler)
+			},
+			policies: [][]string{{"admin", "roles", "read"}},
+			wantLog:  "No role holds authz:read, GET /authz can't be used by anyone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			for _, policy := range tt.policies {
+				if _, err := casbinService.GetEnforcer().AddPolicy(policy[0], policy[1], policy[2]); err != nil {
+					t.Fatalf("add policy: %v", err)
+				}
+			}
+
+			r := gin.New()
+			tt.register(&r.RouterGroup)
+
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			err := VerifyRoutes(r, db)
+			log.SetOutput(os.Stderr)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("VerifyRoutes() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("VerifyRoutes() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := logs.String(); (tt.wantLog == "") != (got == "") || !strings.Contains(got, tt.wantLog) {
+				t.Errorf("VerifyRoutes() logged %q, want %q", got, tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team member statuses
+const (
+	MemberStatusInvited = "invited"
+	MemberStatusActive  = "active"
+)
+
+// NovelTeamMember is a user's membership in the team working on a novel
+// The role only applies on that novel, through a Casbin domain role once the member is active
+type NovelTeamMember struct {
+	gorm.Model
+	NovelID    uint       `json:"novel_id" gorm:"not null;uniqueIndex:idx_novel_team_member"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_novel_team_member;index"`
+	RoleID     uint       `json:"role_id" gorm:"not null"`
+	Status     string     `json:"status" gorm:"not null;size:20"`
+	InvitedBy  *uint      `json:"invited_by"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+func (NovelTeamMember) TableName() string {
+	return "novel_team_members"
+}
+
+// IsActive reports whether the member has joined the team
+func (m *NovelTeamMember) IsActive() bool {
+	return m.Status == MemberStatusActive
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/team/model"
+	"gorm.io/gorm"
+)
+
+// TeamMemberRepository handles novel team membership persistence
+type TeamMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamMemberRepository(db *gorm.DB) *TeamMemberRepository {
+	return &TeamMemberRepository{db: db}
+}
+
+func (r *TeamMemberRepository) WithTx(tx *gorm.DB) *TeamMemberRepository {
+	return &TeamMemberRepository{db: tx}
+}
+
+func (r *TeamMemberRepository) Create(member *model.NovelTeamMember) error {
+	return r.db.Create(member).Error
+}
+
+// GetByNovelAndUser gets a user's membership in a novel team
+func (r *TeamMemberRepository) GetByNovelAndUser(novelID, userID uint) (*model.NovelTeamMember, error) {
+	var member model.NovelTeamMember
+	if err := r.db.Where("novel_id = ? AND user_id = ?", novelID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetByNovelID gets all members and pending invitations of a novel team
+func (r *TeamMemberRepository) GetByNovelID(novelID uint) ([]model.NovelTeamMember, error) {
+	var members []model.NovelTeamMember
+	err := r.db.Where("novel_id = ?", novelID).Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+// UpdateRole changes a member's team role
+func (r *TeamMemberRepository) UpdateRole(id, roleID uint) error {
+	return r.db.Model(&model.NovelTeamMember{}).Where("id = ?", id).Update("role_id", roleID).Error
+}
+
+// Activate marks an invitation as accepted
+func (r *TeamMemberRepository) Activate(id uint, acceptedAt time.Time) error {
+	return r.db.Model(&model.NovelTeamMember{}).Where("id = ?", id).Updates(map[string]any{
+		"status":      model.MemberStatusActive,
+		"accepted_at": acceptedAt,
+	}).Error
+}
+
+// Delete removes a membership for good, so the user can be invited again
+func (r *TeamMemberRepository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&model.NovelTeamMember{}, id).Error
+}
+
+// NovelExists checks if a novel exists and is not deleted
+func (r *TeamMemberRepository) NovelExists(novelID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("novels").Where("id = ? AND deleted_at IS NULL", novelID).Count(&count).Error
+	return count > 0, err
+}
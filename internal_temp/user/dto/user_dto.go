@@ -1,7 +1,13 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/user/model"
+)
+
 type RegisterUserDTO struct {
-	Username        string `json:"username" validate:"required"`
+	Username        string `json:"username" validate:"required,min=3,max=30"`
 	Email           string `json:"email" validate:"required,email"`
 	Password        string `json:"password" validate:"required,min=8"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
@@ -13,12 +19,13 @@ type LoginUserDTO struct {
 }
 
 type UserResponseDTO struct {
-	ID        uint    `json:"id"`
-	Username  *string `json:"username"`
-	Email     string  `json:"email"`
-	AvatarURL *string `json:"avatar_url,omitempty"`
-	Bio       *string `json:"bio,omitempty"`
-	Status    string  `json:"status"`
+	ID            uint    `json:"id"`
+	Username      *string `json:"username"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"email_verified"`
+	AvatarURL     *string `json:"avatar_url,omitempty"`
+	Bio           *string `json:"bio,omitempty"`
+	Status        string  `json:"status"`
 }
 
 type RoleDTO struct {
@@ -26,23 +33,39 @@ type RoleDTO struct {
 	Name string `json:"name"`
 }
 
+// PermissionDTO is a permission the user has, Source is the role that grants it
 type PermissionDTO struct {
 	Resource string `json:"resource"`
 	Action   string `json:"action"`
+	Source   string `json:"source,omitempty"`
 }
 
 type UserWithPermissionsDTO struct {
-	ID          uint            `json:"id"`
-	Username    *string         `json:"username"`
-	Email       string          `json:"email"`
-	AvatarURL   *string         `json:"avatar_url,omitempty"`
-	Bio         *string         `json:"bio,omitempty"`
-	Status      string          `json:"status"`
-	Roles       []RoleDTO       `json:"roles"`
-	Permissions []PermissionDTO `json:"permissions"`
+	ID            uint            `json:"id"`
+	Username      *string         `json:"username"`
+	Email         string          `json:"email"`
+	EmailVerified bool            `json:"email_verified"`
+	AvatarMediaID *uint           `json:"avatar_media_id,omitempty"`
+	AvatarURL     *string         `json:"avatar_url,omitempty"`
+	Bio           *string         `json:"bio,omitempty"`
+	Status        string          `json:"status"`
+	Roles         []RoleDTO       `json:"roles"`
+	Permissions   []PermissionDTO `json:"permissions"`
 }
 
 type AuthResponseDTO struct {
-	Token string          `json:"token"`
-	User  UserResponseDTO `json:"user"`
+	Token        string          `json:"token,omitempty"` // empty until the email is verified, when verification is required
+	RefreshToken string          `json:"refresh_token,omitempty"`
+	ExpiresIn    int64           `json:"expires_in,omitempty"` // access token lifetime in seconds
+	User         UserResponseDTO `json:"user"`
+}
+
+// PublicProfileDTO is what anyone can see about a user
+// It must never include the email address or account status details
+type PublicProfileDTO struct {
+	Handle          string                 `json:"handle"`
+	AvatarURL       *string                `json:"avatar_url,omitempty"`
+	Bio             *string                `json:"bio,omitempty"`
+	JoinedAt        time.Time              `json:"joined_at"`
+	TranslatedWorks []model.TranslatedWork `json:"translated_works"`
 }
@@ -3,7 +3,6 @@ package handler
 import (
 	"net/http"
 
-	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
 	"github.com/FeisalDy/nogo/internal/user/dto"
 	"github.com/FeisalDy/nogo/internal/user/service"
@@ -23,54 +22,16 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 // Note: Register has been moved to the application layer (internal/application/handler/auth_handler.go)
 // This is because registration involves cross-domain operations (creating user + assigning role)
 
-func (h *UserHandler) Login(c *gin.Context) {
-	var loginDTO dto.LoginUserDTO
-	if err := c.ShouldBindJSON(&loginDTO); err != nil {
-		utils.RespondValidationError(c, err, errors.ErrCodeUserValidation)
-		return
-	}
-
-	if err := h.validator.Struct(loginDTO); err != nil {
-		utils.RespondValidationError(c, err, errors.ErrCodeUserValidation)
-		return
-	}
-
-	user, err := h.userService.Login(&loginDTO)
-	if err != nil {
-		utils.HandleServiceError(c, err)
-		return
-	}
-
-	username := ""
-	if user.Username != nil {
-		username = *user.Username
-	}
-	token, err := utils.GenerateToken(user.ID, user.Email, username)
-	if err != nil {
-		utils.HandleServiceError(c, err)
-		return
-	}
-
-	// Prepare response
-	response := dto.AuthResponseDTO{
-		Token: token,
-		User: dto.UserResponseDTO{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			AvatarURL: user.AvatarURL,
-			Bio:       user.Bio,
-			Status:    user.Status,
-		},
-	}
-
-	utils.RespondSuccess(c, http.StatusOK, response, "Login successful")
-}
+// Note: Login has been moved to the application layer (internal/application/handler/auth_handler.go)
+// This is because login now issues refresh tokens, which live in the auth domain
+// New endpoint: POST /api/v1/auth/login
 
 // Note: GetMe has been moved to the application layer (internal/application/handler/user_profile_handler.go)
 // This is because it involves cross-domain operations (user + roles + permissions from Casbin)
 // New endpoint: GET /api/v1/profile/me
 
+// GetUserByEmail looks up a user by email, for admins
+// GET /api/v1/users/:email
 func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	emailParam := c.Param("email")
 
@@ -81,13 +42,26 @@ func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	}
 
 	res := dto.UserResponseDTO{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		AvatarURL: user.AvatarURL,
-		Bio:       user.Bio,
-		Status:    user.Status,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		AvatarURL:     user.AvatarURL,
+		Bio:           user.Bio,
+		Status:        user.Status,
 	}
 
 	utils.RespondSuccess(c, http.StatusOK, res)
 }
+
+// GetPublicProfile returns the public profile of a user by handle, without the email
+// GET /api/v1/users/@:handle
+func (h *UserHandler) GetPublicProfile(c *gin.Context) {
+	profile, err := h.userService.GetPublicProfile(c.Param("handle"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, http.StatusOK, profile)
+}
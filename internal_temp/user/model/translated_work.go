@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// TranslatedWork is a read-only view of a novel translation credited to a user
+// It is loaded from novel_translations for public profiles and has no table of its own
+type TranslatedWork struct {
+	NovelID   uint      `json:"novel_id"`
+	Title     string    `json:"title"`
+	Language  string    `json:"language"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,16 +1,62 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Account statuses
+// A suspension ends on its own at StatusUntil; banned and deactivated accounts stay
+// that way until an admin changes the status
+const (
+	StatusActive      = "active"
+	StatusSuspended   = "suspended"
+	StatusBanned      = "banned"
+	StatusDeactivated = "deactivated"
+)
+
 type User struct {
 	gorm.Model
 
 	Username  *string `json:"username"`
 	Email     string  `json:"email" gorm:"unique;not null;index"`
 	Password  *string `json:"-"`
-	AvatarURL *string `json:"avatar_url"`
+	AvatarURL *string `json:"avatar_url"` // URL of the avatar media, kept in sync by the profile service
 	Bio       *string `json:"bio" gorm:"type:text"`
 	Status    string  `json:"status" gorm:"default:'active';index"`
+
+	StatusReason    *string    `json:"status_reason,omitempty" gorm:"type:text"`
+	StatusUntil     *time.Time `json:"status_until,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	StatusChangedBy *uint      `json:"status_changed_by,omitempty"`
+
+	AvatarMediaID   *uint      `json:"avatar_media_id,omitempty" gorm:"index"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+// IsEmailVerified reports whether the user confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// EffectiveStatus returns the account status at the given time
+// An expired suspension counts as active
+func (u *User) EffectiveStatus(now time.Time) string {
+	if u.Status == "" {
+		return StatusActive
+	}
+	if u.Status == StatusSuspended && u.StatusUntil != nil && !now.Before(*u.StatusUntil) {
+		return StatusActive
+	}
+	return u.Status
+}
+
+// IsValidStatus reports whether status is a known account status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusActive, StatusSuspended, StatusBanned, StatusDeactivated:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserStatusChange records a change of a user's account status, with the reason and the admin who made it
+// ChangedBy is nil for changes made by the system
+type UserStatusChange struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	FromStatus string     `json:"from_status" gorm:"not null;size:20"`
+	ToStatus   string     `json:"to_status" gorm:"not null;size:20"`
+	Reason     *string    `json:"reason" gorm:"type:text"`
+	Until      *time.Time `json:"until"`
+	ChangedBy  *uint      `json:"changed_by" gorm:"index"`
+}
+
+func (UserStatusChange) TableName() string {
+	return "user_status_changes"
+}
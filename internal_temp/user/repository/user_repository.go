@@ -1,11 +1,25 @@
 package repository
 
 import (
+	stdErrors "errors"
+	"time"
+
 	commonModel "github.com/FeisalDy/nogo/internal/common/model"
 	"github.com/FeisalDy/nogo/internal/user/model"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// handleIndex is the case-insensitive unique index on usernames, see migration 019
+const handleIndex = "idx_users_username_lower"
+
+// IsHandleConflict reports whether a write was rejected because another user has the username
+// EnsureHandleAvailable checks this up front, but two requests can still race for the same name
+func IsHandleConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return stdErrors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == handleIndex
+}
+
 // UserRepository handles user-related database operations
 type UserRepository struct {
 	db *gorm.DB
@@ -46,15 +60,117 @@ func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
+// GetUserByHandle gets a user by username, ignoring letter case
+func (r *UserRepository) GetUserByHandle(handle string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("LOWER(username) = LOWER(?)", handle).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetTranslatedWorks gets the novel translations a user made, newest first
+// Translations of deleted novels are left out
+func (r *UserRepository) GetTranslatedWorks(userID uint) ([]model.TranslatedWork, error) {
+	var works []model.TranslatedWork
+	err := r.db.
+		Table("novel_translations").
+		Select("novel_translations.novel_id, novel_translations.title, novel_translations.language, novel_translations.created_at").
+		Joins("JOIN novels ON novels.id = novel_translations.novel_id AND novels.deleted_at IS NULL").
+		Where("novel_translations.translator_id = ? AND novel_translations.deleted_at IS NULL", userID).
+		Order("novel_translations.created_at DESC").
+		Scan(&works).Error
+	return works, err
+}
+
+// MarkEmailVerified marks the user's email as verified, but only while it is still the given address
+// Returns false if the user no longer has that email
+func (r *UserRepository) MarkEmailVerified(userID uint, email string, verifiedAt time.Time) (bool, error) {
+	result := r.db.
+		Model(&model.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified_at", verifiedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdatePassword replaces the user's password hash
+func (r *UserRepository) UpdatePassword(userID uint, hashedPassword string) error {
+	return r.db.
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("password", hashedPassword).Error
+}
+
+// UpdateProfile updates the given profile columns of a user
+func (r *UserRepository) UpdateProfile(userID uint, updates map[string]any) error {
+	return r.db.
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(updates).Error
+}
+
+// ChangeEmail switches the user to a new, already confirmed email address
+func (r *UserRepository) ChangeEmail(userID uint, email string, verifiedAt time.Time) error {
+	return r.db.
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"email":             email,
+			"email_verified_at": verifiedAt,
+		}).Error
+}
+
+// UpdateStatus sets the user's account status and its details
+func (r *UserRepository) UpdateStatus(userID uint, status string, reason *string, until *time.Time, changedBy *uint, changedAt time.Time) error {
+	return r.db.
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"status":            status,
+			"status_reason":     reason,
+			"status_until":      until,
+			"status_changed_at": changedAt,
+			"status_changed_by": changedBy,
+		}).Error
+}
+
+// CreateStatusChange records a change of a user's account status
+func (r *UserRepository) CreateStatusChange(change *model.UserStatusChange) error {
+	return r.db.Create(change).Error
+}
+
+// GetStatusChanges gets the status history of a user, newest first
+func (r *UserRepository) GetStatusChanges(userID uint) ([]model.UserStatusChange, error) {
+	var changes []model.UserStatusChange
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&changes).Error
+	return changes, err
+}
+
+// GetInactiveUsers gets every user whose account status is not active
+// Only the status columns are loaded
+func (r *UserRepository) GetInactiveUsers() ([]model.User, error) {
+	var users []model.User
+	err := r.db.
+		Select("id", "status", "status_reason", "status_until").
+		Where("status <> ?", model.StatusActive).
+		Find(&users).Error
+	return users, err
+}
+
 // ===== Role-related methods =====
 // Note: These methods only deal with the user_roles junction table (common domain)
 // They work with role IDs only, not role entities (to maintain domain boundaries)
 
 // AssignRoleToUser assigns a role to a user by creating an entry in user_roles table
-func (r *UserRepository) AssignRoleToUser(userID, roleID uint) error {
+// The assignment is permanent when expiresAt is nil
+func (r *UserRepository) AssignRoleToUser(userID, roleID uint, expiresAt *time.Time) error {
 	userRole := commonModel.UserRole{
-		UserID: userID,
-		RoleID: roleID,
+		UserID:    userID,
+		RoleID:    roleID,
+		ExpiresAt: expiresAt,
 	}
 	return r.db.Create(&userRole).Error
 }
@@ -66,6 +182,15 @@ func (r *UserRepository) RemoveRoleFromUser(userID, roleID uint) error {
 		Delete(&commonModel.UserRole{}).Error
 }
 
+// RemoveExpiredRoleFromUser deletes the user's assignment of the role only if it expired at or before now
+// Reports whether it was deleted. An assignment renewed since it was read as expired is kept
+func (r *UserRepository) RemoveExpiredRoleFromUser(userID, roleID uint, now time.Time) (bool, error) {
+	result := r.db.
+		Where("user_id = ? AND role_id = ? AND expires_at IS NOT NULL AND expires_at <= ?", userID, roleID, now).
+		Delete(&commonModel.UserRole{})
+	return result.RowsAffected > 0, result.Error
+}
+
 // GetUserRoleIDs gets all role IDs for a user from user_roles table
 func (r *UserRepository) GetUserRoleIDs(userID uint) ([]uint, error) {
 	var roleIDs []uint
@@ -76,6 +201,39 @@ func (r *UserRepository) GetUserRoleIDs(userID uint) ([]uint, error) {
 	return roleIDs, err
 }
 
+// GetUserRoles gets the role assignments of a user from user_roles table
+func (r *UserRepository) GetUserRoles(userID uint) ([]commonModel.UserRole, error) {
+	var userRoles []commonModel.UserRole
+	err := r.db.Where("user_id = ?", userID).Order("role_id").Find(&userRoles).Error
+	return userRoles, err
+}
+
+// GetExpiredUserRoles gets the role assignments that expired at or before the given time
+func (r *UserRepository) GetExpiredUserRoles(now time.Time) ([]commonModel.UserRole, error) {
+	var userRoles []commonModel.UserRole
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&userRoles).Error
+	return userRoles, err
+}
+
+// GetAllUserRoles gets every user-role assignment from user_roles table
+func (r *UserRepository) GetAllUserRoles() ([]commonModel.UserRole, error) {
+	var userRoles []commonModel.UserRole
+	err := r.db.Order("user_id, role_id").Find(&userRoles).Error
+	return userRoles, err
+}
+
+// HasActiveRoleByID checks if a user has a specific role that hasn't expired at the given time
+// An expired assignment counts as absent even before the expiry sweeper removes it
+func (r *UserRepository) HasActiveRoleByID(userID, roleID uint, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.
+		Model(&commonModel.UserRole{}).
+		Where("user_id = ? AND role_id = ?", userID, roleID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // HasRoleByID checks if a user has a specific role by checking user_roles table
 // Only checks the junction table, doesn't access the roles table
 func (r *UserRepository) HasRoleByID(userID, roleID uint) (bool, error) {
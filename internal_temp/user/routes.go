@@ -15,11 +15,12 @@ func RegisterRoutes(db *gorm.DB, router *gin.RouterGroup) {
 	userService := service.NewUserService(userRepository)
 	userHandler := handler.NewUserHandler(userService)
 
-	router.POST("/login", userHandler.Login)
+	middleware.DeclareRoutes(router).Public().GET("/@:handle", userHandler.GetPublicProfile)
 
+	// Looking users up by email exposes the address, so it is limited to admins
 	protected := router.Group("/")
 	protected.Use(middleware.AuthMiddleware())
 	{
-		protected.GET("/:email", userHandler.GetUserByEmail)
+		middleware.DeclareRoutes(protected).Permission("users", "read").GET("/:email", userHandler.GetUserByEmail)
 	}
 }
@@ -0,0 +1,110 @@
+package service
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/user/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	minHandleLength = 3
+	maxHandleLength = 30
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
+// reservedHandles can't be registered by anyone, compared case-insensitively
+// They would be confusing in URLs and mentions or could be used to impersonate staff
+var reservedHandles = map[string]bool{
+	"about": true, "account": true, "admin": true, "administrator": true, "anonymous": true,
+	"api": true, "auth": true, "everyone": true, "help": true, "login": true,
+	"logout": true, "me": true, "mod": true, "moderator": true, "nogo": true,
+	"null": true, "official": true, "privacy": true, "profile": true, "register": true,
+	"root": true, "security": true, "settings": true, "signup": true, "staff": true,
+	"support": true, "system": true, "terms": true, "undefined": true, "user": true,
+	"users": true,
+}
+
+// ValidateHandle checks the format of a handle and that it isn't reserved
+func ValidateHandle(handle string) error {
+	if !handlePattern.MatchString(handle) {
+		return errors.ErrUserHandleInvalid
+	}
+	if reservedHandles[strings.ToLower(handle)] {
+		return errors.ErrUserHandleReserved
+	}
+	return nil
+}
+
+// EnsureHandleAvailable validates a handle and checks that no other user has it in any letter case
+// Pass the ID of the user changing their handle as exceptUserID, or 0 for a new user
+func EnsureHandleAvailable(repo *repository.UserRepository, handle string, exceptUserID uint) error {
+	if err := ValidateHandle(handle); err != nil {
+		return err
+	}
+
+	existingUser, err := repo.GetUserByHandle(handle)
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existingUser.ID != exceptUserID {
+		return errors.ErrUserHandleTaken
+	}
+	return nil
+}
+
+// HandleConflictError turns a write rejected by the unique username index into ErrUserHandleTaken
+// and returns other errors unchanged
+func HandleConflictError(err error) error {
+	if repository.IsHandleConflict(err) {
+		return errors.ErrUserHandleTaken
+	}
+	return err
+}
+
+// GenerateHandle derives a free, valid handle from a display name or email local part
+// Used for accounts created by social login, where the user didn't pick one
+func GenerateHandle(repo *repository.UserRepository, base string) (string, error) {
+	var b strings.Builder
+	for _, r := range base {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case r == ' ', r == '.', r == '-':
+			b.WriteRune('_')
+		}
+	}
+
+	handle := strings.Trim(b.String(), "_")
+	// Leave room for a numeric suffix
+	if len(handle) > maxHandleLength-5 {
+		handle = handle[:maxHandleLength-5]
+	}
+	if len(handle) < minHandleLength {
+		handle = "user"
+	}
+
+	candidate := handle
+	for attempt := 0; attempt < 10; attempt++ {
+		err := EnsureHandleAvailable(repo, candidate, 0)
+		if err == nil {
+			return candidate, nil
+		}
+		if !stdErrors.Is(err, errors.ErrUserHandleTaken) && !stdErrors.Is(err, errors.ErrUserHandleReserved) {
+			return "", err
+		}
+
+		candidate = fmt.Sprintf("%s_%04d", handle, rand.IntN(10000))
+	}
+
+	return "", errors.ErrUserHandleTaken
+}
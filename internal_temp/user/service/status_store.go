@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/FeisalDy/nogo/internal/common/errors"
+	"github.com/FeisalDy/nogo/internal/user/model"
+	"github.com/FeisalDy/nogo/internal/user/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	statusStore     *StatusStore
+	statusStoreOnce sync.Once
+)
+
+// StatusStore keeps the users that are not active in memory so that
+// AuthMiddleware can refuse them on every request without a query
+// The users table is the source of truth; the cache is reloaded periodically
+// to pick up status changes made by other instances
+type StatusStore struct {
+	repo *repository.UserRepository
+
+	mu       sync.RWMutex
+	inactive map[uint]model.User // user id -> status columns
+}
+
+// InitStatusStore creates the singleton status store and loads the users that are not active
+func InitStatusStore(db *gorm.DB) (*StatusStore, error) {
+	var err error
+	statusStoreOnce.Do(func() {
+		store := &StatusStore{
+			repo:     repository.NewUserRepository(db),
+			inactive: make(map[uint]model.User),
+		}
+
+		if syncErr := store.Sync(); syncErr != nil {
+			err = fmt.Errorf("failed to load user statuses: %w", syncErr)
+			return
+		}
+
+		statusStore = store
+	})
+
+	return statusStore, err
+}
+
+func GetStatusStore() *StatusStore {
+	return statusStore
+}
+
+// Check returns an error if the user's account is not active
+func (s *StatusStore) Check(userID uint) error {
+	s.mu.RLock()
+	user, ok := s.inactive[userID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	status := user.EffectiveStatus(time.Now())
+	if status == model.StatusActive {
+		return nil
+	}
+	return errors.NewAppError(errors.ErrCodeAuthAccountInactive, errors.ErrAuthAccountInactive.Message).
+		WithDetails(statusDetails(&user, status))
+}
+
+// Set updates the cached status of a user after a status change
+func (s *StatusStore) Set(user *model.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.Status == model.StatusActive {
+		delete(s.inactive, user.ID)
+		return
+	}
+	s.inactive[user.ID] = model.User{
+		Model:        user.Model,
+		Status:       user.Status,
+		StatusReason: user.StatusReason,
+		StatusUntil:  user.StatusUntil,
+	}
+}
+
+// Sync reloads the users that are not active
+func (s *StatusStore) Sync() error {
+	users, err := s.repo.GetInactiveUsers()
+	if err != nil {
+		return err
+	}
+
+	inactive := make(map[uint]model.User, len(users))
+	for _, user := range users {
+		inactive[user.ID] = user
+	}
+
+	s.mu.Lock()
+	s.inactive = inactive
+	s.mu.Unlock()
+	return nil
+}
+
+// StartSync periodically reloads the cache in the background
+func (s *StatusStore) StartSync(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.Sync(); err != nil {
+				log.Printf("Warning: Failed to sync user statuses: %v", err)
+			}
+		}
+	}()
+}
+
+// statusDetails describes a status that is not active for error responses
+func statusDetails(user *model.User, status string) map[string]any {
+	details := map[string]any{"status": status}
+	if user.StatusReason != nil {
+		details["reason"] = *user.StatusReason
+	}
+	if status == model.StatusSuspended && user.StatusUntil != nil {
+		details["until"] = user.StatusUntil
+	}
+	return details
+}
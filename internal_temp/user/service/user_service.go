@@ -1,15 +1,22 @@
 package service
 
 import (
+	"log"
+	"strings"
+	"time"
+
 	"github.com/FeisalDy/nogo/internal/common/errors"
 	"github.com/FeisalDy/nogo/internal/common/utils"
+	"github.com/FeisalDy/nogo/internal/database"
 	"github.com/FeisalDy/nogo/internal/user/dto"
 	"github.com/FeisalDy/nogo/internal/user/model"
 	"github.com/FeisalDy/nogo/internal/user/repository"
+	"gorm.io/gorm"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo                 *repository.UserRepository
+	requireEmailVerification bool
 }
 
 func NewUserService(userRepository *repository.UserRepository) *UserService {
@@ -18,31 +25,10 @@ func NewUserService(userRepository *repository.UserRepository) *UserService {
 	}
 }
 
-// CreateUser creates a new user without role assignment
-// Role assignment should be handled by the application layer
-func (s *UserService) CreateUser(registerDTO *dto.RegisterUserDTO) (*model.User, error) {
-	existingUser, _ := s.userRepo.GetUserByEmail(registerDTO.Email)
-	if existingUser != nil {
-		return nil, errors.ErrUserAlreadyExists
-	}
-
-	hashedPassword, err := utils.HashPassword(registerDTO.Password)
-	if err != nil {
-		return nil, err
-	}
-
-	user := &model.User{
-		Username: &registerDTO.Username,
-		Email:    registerDTO.Email,
-		Password: &hashedPassword,
-	}
-
-	userCreated, err := s.userRepo.CreateUser(user)
-	if err != nil {
-		return nil, err
-	}
-
-	return userCreated, nil
+// RequireEmailVerification makes Login refuse users whose email is not verified yet
+func (s *UserService) RequireEmailVerification(required bool) *UserService {
+	s.requireEmailVerification = required
+	return s
 }
 
 func (s *UserService) Login(loginDTO *dto.LoginUserDTO) (*model.User, error) {
@@ -59,9 +45,37 @@ func (s *UserService) Login(loginDTO *dto.LoginUserDTO) (*model.User, error) {
 		return nil, errors.ErrUserInvalidCredentials
 	}
 
+	// Upgrade hashes made with an older algorithm or a lower cost while the plain password is at hand
+	if utils.PasswordNeedsRehash(*user.Password) {
+		if hashedPassword, err := utils.HashPassword(loginDTO.Password); err != nil {
+			log.Printf("Failed to rehash password of user %d: %v", user.ID, err)
+		} else if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+			log.Printf("Failed to store rehashed password of user %d: %v", user.ID, err)
+		} else {
+			user.Password = &hashedPassword
+		}
+	}
+
+	// Checked after the password so the response doesn't reveal which emails are registered
+	if err := s.EnsureCanLogin(user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
+// EnsureCanLogin checks whether an authenticated user may get a session
+// Shared by password login and logins through external providers
+func (s *UserService) EnsureCanLogin(user *model.User) error {
+	if err := checkStatus(user); err != nil {
+		return err
+	}
+	if s.requireEmailVerification && !user.IsEmailVerified() {
+		return errors.ErrUserEmailNotVerified
+	}
+	return nil
+}
+
 func (s *UserService) GetUserByID(id uint) (*model.User, error) {
 	user, err := s.userRepo.GetUserByID(id)
 	if err != nil {
@@ -87,3 +101,143 @@ func (s *UserService) GetUserByEmail(email string) (*model.User, error) {
 
 	return user, nil
 }
+
+// GetPublicProfile gets the public profile of a user by handle
+// Accounts that are not active are reported as not found
+func (s *UserService) GetPublicProfile(handle string) (*dto.PublicProfileDTO, error) {
+	user, err := s.userRepo.GetUserByHandle(handle)
+	if err != nil || user.Username == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	if user.EffectiveStatus(time.Now()) != model.StatusActive {
+		return nil, errors.ErrUserNotFound
+	}
+
+	works, err := s.userRepo.GetTranslatedWorks(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PublicProfileDTO{
+		Handle:          *user.Username,
+		AvatarURL:       user.AvatarURL,
+		Bio:             user.Bio,
+		JoinedAt:        user.CreatedAt,
+		TranslatedWorks: works,
+	}, nil
+}
+
+// CheckPassword verifies the current password of a user before a credential change
+// Users without a password (social login only) can't confirm one, they have to re-authenticate instead
+func (s *UserService) CheckPassword(user *model.User, password string) error {
+	if user.Password == nil {
+		return errors.ErrAuthReauthRequired
+	}
+	if !utils.ComparePassword(*user.Password, password) {
+		return errors.ErrUserWrongPassword
+	}
+	return nil
+}
+
+// SetPassword sets a new password, the caller must have confirmed the user's identity
+func (s *UserService) SetPassword(userID uint, newPassword string) error {
+	if err := utils.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdatePassword(userID, hashedPassword)
+}
+
+// ChangeStatus changes the account status of a user and records the change
+// until is required for, and only kept with, a suspension. changedBy is the admin
+// making the change and can't be the user themselves
+func (s *UserService) ChangeStatus(userID uint, status, reason string, until *time.Time, changedBy uint) (*model.User, error) {
+	if !model.IsValidStatus(status) {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Unknown account status "+status)
+	}
+	if userID == changedBy {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "You can't change the status of your own account")
+	}
+
+	now := time.Now()
+	if status == model.StatusSuspended {
+		if until == nil || !until.After(now) {
+			return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "A suspension needs an end time in the future")
+		}
+	} else {
+		until = nil
+	}
+
+	var reasonPtr *string
+	if reason = strings.TrimSpace(reason); reason != "" {
+		reasonPtr = &reason
+	}
+
+	var user *model.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		repo := s.userRepo.WithTx(tx)
+
+		var err error
+		user, err = repo.GetUserByID(userID)
+		if err != nil {
+			return errors.ErrUserNotFound
+		}
+
+		if err := repo.UpdateStatus(userID, status, reasonPtr, until, &changedBy, now); err != nil {
+			return err
+		}
+
+		if err := repo.CreateStatusChange(&model.UserStatusChange{
+			UserID:     userID,
+			FromStatus: user.EffectiveStatus(now),
+			ToStatus:   status,
+			Reason:     reasonPtr,
+			Until:      until,
+			ChangedBy:  &changedBy,
+		}); err != nil {
+			return err
+		}
+
+		user.Status = status
+		user.StatusReason = reasonPtr
+		user.StatusUntil = until
+		user.StatusChangedAt = &now
+		user.StatusChangedBy = &changedBy
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if store := GetStatusStore(); store != nil {
+		store.Set(user)
+	}
+	return user, nil
+}
+
+// GetStatusHistory gets the status changes of a user, newest first
+func (s *UserService) GetStatusHistory(userID uint) ([]model.UserStatusChange, error) {
+	return s.userRepo.GetStatusChanges(userID)
+}
+
+// checkStatus refuses a login to an account that is not active
+func checkStatus(user *model.User) error {
+	var base *errors.AppError
+	status := user.EffectiveStatus(time.Now())
+	switch status {
+	case model.StatusActive:
+		return nil
+	case model.StatusSuspended:
+		base = errors.ErrUserSuspended
+	case model.StatusBanned:
+		base = errors.ErrUserBanned
+	default:
+		base = errors.ErrUserDeactivated
+	}
+	return errors.NewAppError(base.Code, base.Message).WithDetails(statusDetails(user, status))
+}